@@ -0,0 +1,52 @@
+package extism_pdk
+
+// Arena tracks blocks of host memory so they can all be freed at once.
+// Create one per call and defer FreeAll:
+//
+//	arena := host.NewArena()
+//	defer arena.FreeAll()
+//
+// Blocks owned by an arena must not be freed individually, or FreeAll will
+// free them a second time. Blocks passed to the host, such as an output
+// buffer, must not be tracked.
+type Arena struct {
+	blocks []Memory
+}
+
+// NewArena returns an empty arena
+func (h Host) NewArena() *Arena {
+	return &Arena{}
+}
+
+// Alloc allocates a block of host memory holding data and tracks it,
+// returning ErrAllocFailed if the host can't allocate it
+func (a *Arena) Alloc(data []byte) (Memory, error) {
+	mem, err := allocateMemory(data)
+	if err != nil {
+		return Memory{}, err
+	}
+	return a.Track(mem), nil
+}
+
+// Track adds a block, such as one returned by a custom host function, to
+// the arena and returns it. Blocks with offset 0 are ignored.
+func (a *Arena) Track(mem Memory) Memory {
+	if mem.offset != 0 {
+		a.blocks = append(a.blocks, mem)
+	}
+	return mem
+}
+
+// Len returns the number of blocks the arena holds
+func (a *Arena) Len() int {
+	return len(a.blocks)
+}
+
+// FreeAll frees every tracked block and empties the arena, so calling it
+// again frees nothing
+func (a *Arena) FreeAll() {
+	for _, mem := range a.blocks {
+		mem.Free()
+	}
+	a.blocks = nil
+}
@@ -0,0 +1,60 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+func TestArenaFreeAll(t *testing.T) {
+	m := &testhost.MockHost{}
+	h := m.Host()
+	arena := h.NewArena()
+
+	var want []uint64
+	for _, data := range []string{"a", "bb", "ccc"} {
+		mem, err := arena.Alloc([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, mem.Offset())
+	}
+	want = append(want, arena.Track(extism_pdk.AllocateMemory([]byte("tracked"))).Offset())
+	arena.Track(extism_pdk.Memory{})
+	if arena.Len() != 4 {
+		t.Fatalf("Len = %d, want 4", arena.Len())
+	}
+
+	arena.FreeAll()
+	arena.FreeAll()
+
+	got := append([]uint64{}, m.Frees...)
+	sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+	sort.Slice(want, func(i, j int) bool { return want[i] < want[j] })
+	if len(got) != len(want) {
+		t.Fatalf("Frees = %v, want each of %v once", m.Frees, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Frees = %v, want each of %v once", m.Frees, want)
+		}
+	}
+	if arena.Len() != 0 {
+		t.Fatalf("Len after FreeAll = %d", arena.Len())
+	}
+}
+
+func TestArenaAllocFailure(t *testing.T) {
+	m := &testhost.MockHost{FailAlloc: true}
+	arena := m.Host().NewArena()
+	if _, err := arena.Alloc([]byte("data")); err == nil {
+		t.Fatal("Alloc succeeded")
+	}
+	if arena.Len() != 0 {
+		t.Fatalf("Len = %d, want nothing tracked", arena.Len())
+	}
+}
@@ -0,0 +1,93 @@
+package extism_pdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// RequestBuilder builds an HTTPRequest through chained calls:
+//
+//	resp, err := extism_pdk.NewRequest("POST", url).
+//		BearerToken(token).
+//		JSON(payload).
+//		Do(host)
+//
+// Errors from building, such as a value that can't be marshaled, are kept
+// and returned by Do.
+type RequestBuilder struct {
+	req HTTPRequest
+	err error
+}
+
+// NewRequest starts building a request with the given method and URL
+func NewRequest(method, url string) *RequestBuilder {
+	return &RequestBuilder{req: HTTPRequest{Method: method, URL: url}}
+}
+
+// Header sets a request header, replacing any existing value regardless of
+// case
+func (b *RequestBuilder) Header(name, value string) *RequestBuilder {
+	b.req.setHeader(name, value)
+	return b
+}
+
+// Body sets the request body and, if contentType is not empty, its
+// Content-Type header
+func (b *RequestBuilder) Body(body []byte, contentType string) *RequestBuilder {
+	b.req.BodyBytes = body
+	if contentType != "" {
+		b.req.setHeader("Content-Type", contentType)
+	}
+	return b
+}
+
+// JSON marshals v as the request body with a Content-Type of
+// application/json
+func (b *RequestBuilder) JSON(v interface{}) *RequestBuilder {
+	data, err := json.Marshal(v)
+	if err != nil {
+		b.err = fmt.Errorf("invalid JSON request body: %w", err)
+		return b
+	}
+	return b.Body(data, "application/json")
+}
+
+// BasicAuth sets the Authorization header for HTTP basic authentication
+func (b *RequestBuilder) BasicAuth(user, pass string) *RequestBuilder {
+	b.req.SetBasicAuth(user, pass)
+	return b
+}
+
+// BearerToken sets the Authorization header to send token as a bearer token
+func (b *RequestBuilder) BearerToken(token string) *RequestBuilder {
+	b.req.SetBearerToken(token)
+	return b
+}
+
+// Timeout sets the request's timeout hint; see HTTPWithTimeout
+func (b *RequestBuilder) Timeout(timeoutMs int) *RequestBuilder {
+	b.req.TimeoutMs = timeoutMs
+	return b
+}
+
+// Build returns the request built so far. It does not validate the request;
+// use Do to get building errors.
+func (b *RequestBuilder) Build() HTTPRequest {
+	return b.req
+}
+
+// Do validates the request and makes it with host. An empty method or URL,
+// or an earlier building error, is returned without making the request.
+func (b *RequestBuilder) Do(host HostAPI) (*HTTPResponse, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.req.Method == "" {
+		return nil, errors.New("HTTP request method is empty")
+	}
+	if b.req.URL == "" {
+		return nil, errors.New("HTTP request URL is empty")
+	}
+	return host.HTTP(b.req)
+}
@@ -0,0 +1,39 @@
+// Package cbor provides CBOR input and output helpers for Extism plugins. It
+// lives outside the core extism_pdk package so that only plugins which
+// import it depend on github.com/fxamacker/cbor/v2.
+//
+// Importing the package registers its decoder and encoder with
+// extism_pdk.RegisterInputDecoder and extism_pdk.RegisterOutputEncoder, so
+// Host.DecodeInput accepts CBOR input and Host.RespondNegotiated can produce
+// CBOR output.
+package cbor
+
+import (
+	"github.com/fxamacker/cbor/v2"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+)
+
+func init() {
+	extism_pdk.RegisterInputDecoder("application/cbor", func(h extism_pdk.Host, v interface{}) error {
+		return GetInputCBOR(h, v)
+	})
+	extism_pdk.RegisterOutputEncoder("application/cbor", func(h extism_pdk.Host, v interface{}) error {
+		return SetOutputCBOR(h, v)
+	})
+}
+
+// GetInputCBOR unmarshals the CBOR input into the provided interface. It
+// mirrors HostAPI.GetInputJSON.
+func GetInputCBOR(host extism_pdk.HostAPI, v interface{}) error {
+	return cbor.Unmarshal(host.GetInput(), v)
+}
+
+// SetOutputCBOR marshals the provided interface to CBOR and sets it as
+// output. It mirrors HostAPI.SetOutputJSON.
+func SetOutputCBOR(host extism_pdk.HostAPI, v interface{}) error {
+	data, err := cbor.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return host.SetOutput(data)
+}
@@ -0,0 +1,39 @@
+//go:build !tinygo && !wasip1
+
+package cbor_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/cbor"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+type reading struct {
+	Device string
+	Tags   map[string]map[string]int
+	Raw    []byte
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := reading{
+		Device: "sensor-1",
+		Tags:   map[string]map[string]int{"room": {"floor": 2, "desk": 7}},
+		Raw:    []byte{0x00, 0xff, 0x10},
+	}
+
+	m := &testhost.MockHost{}
+	if err := cbor.SetOutputCBOR(m, in); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Input = m.Output
+	var out reading
+	if err := cbor.GetInputCBOR(m, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip = %+v, want %+v", out, in)
+	}
+}
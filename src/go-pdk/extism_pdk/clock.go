@@ -0,0 +1,43 @@
+package extism_pdk
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ClockHostFunc is the name under which a host clock function can be
+// registered with RegisterHostFunc. It takes no input and returns the
+// current Unix time in milliseconds as a decimal string.
+const ClockHostFunc = "extism_clock_now"
+
+// UnixMillis returns the host's current Unix time in milliseconds. It calls
+// the ClockHostFunc host function if the plugin registered one; otherwise it
+// falls back to time.Now, which reads the WASI clock and only works if the
+// host enables WASI.
+func (h Host) UnixMillis() (int64, error) {
+	if _, ok := hostFuncs[ClockHostFunc]; !ok {
+		return time.Now().UnixMilli(), nil
+	}
+
+	result, err := CallHostFunc(ClockHostFunc, nil)
+	if err != nil {
+		return 0, err
+	}
+	millis, err := strconv.ParseInt(strings.TrimSpace(string(result)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time from host function %q: %w", ClockHostFunc, err)
+	}
+	return millis, nil
+}
+
+// Now returns the host's current wall-clock time, with the same source and
+// fallback as UnixMillis
+func (h Host) Now() (time.Time, error) {
+	millis, err := h.UnixMillis()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(millis), nil
+}
@@ -0,0 +1,71 @@
+package extism_pdk
+
+import (
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// GetInputBase64 decodes the input as standard base64. Surrounding
+// whitespace is ignored.
+func (h Host) GetInputBase64() ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(h.GetInputString()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 input: %w", err)
+	}
+	return data, nil
+}
+
+// SetOutputBase64 encodes data as standard base64 and sets it as output
+func (h Host) SetOutputBase64(data []byte) error {
+	return h.SetOutputString(base64.StdEncoding.EncodeToString(data))
+}
+
+// InputGzipReader returns a reader that decompresses gzip input as it
+// streams it from the host, so neither the compressed nor the decompressed
+// data is held in memory all at once. Concatenated gzip members are read as
+// one stream. It returns an error if the input doesn't start with a valid
+// gzip header; later corruption is reported by Read.
+func (h Host) InputGzipReader() (io.Reader, error) {
+	zr, err := gzip.NewReader(h.InputReader())
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip input: %w", err)
+	}
+	return zr, nil
+}
+
+// GetInputGunzip decompresses gzip input, streaming it from the host
+func (h Host) GetInputGunzip() ([]byte, error) {
+	zr, err := h.InputGzipReader()
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip input: %w", err)
+	}
+	return data, nil
+}
+
+// SetOutputGzip compresses data with gzip and sets it as output. The
+// compressed output is written straight to host memory through an
+// OutputStream. The caller is responsible for decompressing it.
+func (h Host) SetOutputGzip(data []byte) error {
+	stream, err := h.BeginOutput()
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(stream)
+	if _, err := zw.Write(data); err != nil {
+		stream.Discard()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		stream.Discard()
+		return err
+	}
+	return stream.Commit()
+}
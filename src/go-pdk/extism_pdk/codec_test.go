@@ -0,0 +1,94 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+func TestGzipRoundTrip(t *testing.T) {
+	data := []byte(strings.Repeat(`{"id":1,"name":"compressible"}`, 200))
+
+	m := &testhost.MockHost{}
+	h := m.Host()
+	if err := h.SetOutputGzip(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Output) >= len(data) {
+		t.Fatalf("output is %d bytes, not compressed from %d", len(m.Output), len(data))
+	}
+
+	m.Input = m.Output
+	got, err := h.GetInputGunzip()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("round trip = %q", got)
+	}
+}
+
+func TestGetInputGunzipInvalid(t *testing.T) {
+	m := &testhost.MockHost{Input: []byte("not gzip")}
+	if _, err := m.Host().GetInputGunzip(); err == nil {
+		t.Fatal("GetInputGunzip of plain text succeeded")
+	}
+}
+
+// gzipMember compresses data as a single gzip member
+func gzipMember(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestInputGzipReaderMultiMember(t *testing.T) {
+	input := append(gzipMember(t, "first member, "), gzipMember(t, "second member")...)
+	m := &testhost.MockHost{Input: input}
+
+	r, err := m.Host().InputGzipReader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "first member, second member" {
+		t.Fatalf("read %q", got)
+	}
+}
+
+func TestInputGzipReaderMalformed(t *testing.T) {
+	m := &testhost.MockHost{Input: []byte("plain text")}
+	if _, err := m.Host().InputGzipReader(); err == nil {
+		t.Fatal("InputGzipReader of plain text succeeded")
+	}
+
+	// A valid header followed by corrupt data fails on read
+	data := gzipMember(t, strings.Repeat("payload ", 100))
+	for i := 20; i < len(data)-8; i++ {
+		data[i] ^= 0xff
+	}
+	m.Input = data
+	r, err := m.Host().InputGzipReader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(r); err == nil {
+		t.Fatal("reading corrupt gzip succeeded")
+	}
+}
@@ -0,0 +1,168 @@
+package extism_pdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// lookupConfig gets a configuration value by key, reporting whether the key
+// is present
+func (h Host) lookupConfig(key string) (string, bool) {
+	mem, err := allocateMemory([]byte(key))
+	if err != nil {
+		return "", false
+	}
+	defer mem.Free()
+
+	resultPtr := extism_config_get(mem.Offset(), mem.Length())
+	if resultPtr == 0 {
+		return "", false
+	}
+
+	return findMemory(resultPtr).LoadString(), true
+}
+
+// HasConfig reports whether a configuration key is present, even if its
+// value is empty
+func (h Host) HasConfig(key string) bool {
+	_, ok := h.lookupConfig(key)
+	return ok
+}
+
+// RequireConfig checks that every key is present, returning an error
+// wrapping ErrConfigMissing that lists all the missing keys. Keys present
+// with an empty value count as present.
+func (h Host) RequireConfig(keys ...string) error {
+	var missing []string
+	for _, key := range keys {
+		if !h.HasConfig(key) {
+			missing = append(missing, strconv.Quote(key))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrConfigMissing, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// GetConfigOr gets a configuration value by key, returning fallback if the
+// key is absent. A key that is present with an empty value returns "".
+func (h Host) GetConfigOr(key, fallback string) string {
+	value, ok := h.lookupConfig(key)
+	if !ok {
+		return fallback
+	}
+	return value
+}
+
+// MustGetConfig gets a configuration value that the plugin cannot run
+// without. If the key is absent it sets the plugin error and panics.
+func (h Host) MustGetConfig(key string) string {
+	value, ok := h.lookupConfig(key)
+	if !ok {
+		msg := fmt.Sprintf("missing required config key %q", key)
+		h.SetError(msg)
+		panic(msg)
+	}
+	return value
+}
+
+// GetConfigInt gets a configuration value parsed as a base-10 integer. The
+// second return value is false if the key is absent or cannot be parsed.
+func (h Host) GetConfigInt(key string) (int64, bool) {
+	value, err := strconv.ParseInt(strings.TrimSpace(h.GetConfig(key)), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// GetConfigBool gets a configuration value parsed as a boolean. "true", "1"
+// and "yes" are true; "false", "0" and "no" are false (case-insensitive). The
+// second return value is false if the key is absent or cannot be parsed.
+func (h Host) GetConfigBool(key string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(h.GetConfig(key))) {
+	case "true", "1", "yes":
+		return true, true
+	case "false", "0", "no":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// GetConfigFloat gets a configuration value parsed as a float. The second
+// return value is false if the key is absent or cannot be parsed.
+func (h Host) GetConfigFloat(key string) (float64, bool) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(h.GetConfig(key)), 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// GetConfigJSON unmarshals a JSON configuration value into the provided
+// interface, returning an error if the key is absent or the value is not
+// valid JSON
+func (h Host) GetConfigJSON(key string, v interface{}) error {
+	value, ok := h.lookupConfig(key)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrConfigMissing, key)
+	}
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		return fmt.Errorf("invalid JSON in config key %q: %w", key, err)
+	}
+	return nil
+}
+
+// allConfigKeysKey is the reserved configuration key under which a host can
+// list its configuration keys as a JSON array of strings
+const allConfigKeysKey = "__all_keys"
+
+// GetAllConfig returns every configuration value as a map. The Extism
+// runtime has no way to enumerate configuration, so this relies on the host
+// listing the key names as a JSON array in the reserved "__all_keys" key; it
+// returns an error wrapping ErrConfigMissing if that key is absent. Listed
+// keys that are not present are left out of the map.
+func (h Host) GetAllConfig() (map[string]string, error) {
+	var keys []string
+	if err := h.GetConfigJSON(allConfigKeysKey, &keys); err != nil {
+		return nil, err
+	}
+
+	config := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if key == allConfigKeysKey {
+			continue
+		}
+		if value, ok := h.lookupConfig(key); ok {
+			config[key] = value
+		}
+	}
+	return config, nil
+}
+
+// GetConfigWithPrefix returns the configuration values whose keys start with
+// prefix, such as "DB_", with the prefix removed from the keys if strip is
+// set. It enumerates keys with GetAllConfig, so it returns the same error if
+// the host doesn't list its keys in "__all_keys".
+func (h Host) GetConfigWithPrefix(prefix string, strip bool) (map[string]string, error) {
+	all, err := h.GetAllConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	config := map[string]string{}
+	for key, value := range all {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if strip {
+			key = strings.TrimPrefix(key, prefix)
+		}
+		config[key] = value
+	}
+	return config, nil
+}
@@ -0,0 +1,44 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+func TestHasConfig(t *testing.T) {
+	m := &testhost.MockHost{Config: map[string]string{"empty": "", "name": "value"}}
+	h := m.Host()
+
+	if !h.HasConfig("empty") || h.GetConfig("empty") != "" {
+		t.Errorf("empty key: HasConfig = %v, GetConfig = %q", h.HasConfig("empty"), h.GetConfig("empty"))
+	}
+	if !h.HasConfig("name") || h.GetConfig("name") != "value" {
+		t.Errorf("name: HasConfig = %v, GetConfig = %q", h.HasConfig("name"), h.GetConfig("name"))
+	}
+	if h.HasConfig("missing") || h.GetConfig("missing") != "" {
+		t.Errorf("missing key: HasConfig = %v, GetConfig = %q", h.HasConfig("missing"), h.GetConfig("missing"))
+	}
+}
+
+func TestRequireConfig(t *testing.T) {
+	m := &testhost.MockHost{Config: map[string]string{"present": "x", "empty": ""}}
+	h := m.Host()
+
+	if err := h.RequireConfig("present", "empty"); err != nil {
+		t.Fatalf("err = %v, want nil for present and empty keys", err)
+	}
+
+	err := h.RequireConfig("present", "missing_a", "empty", "missing_b")
+	if !errors.Is(err, extism_pdk.ErrConfigMissing) {
+		t.Fatalf("err = %v, want ErrConfigMissing", err)
+	}
+	if !strings.Contains(err.Error(), `"missing_a", "missing_b"`) || strings.Contains(err.Error(), `"empty"`) {
+		t.Fatalf("err = %v, want exactly the missing keys listed", err)
+	}
+}
@@ -0,0 +1,154 @@
+package extism_pdk
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// InputContentTypeKey is the reserved variable or configuration key from
+// which DecodeInput reads the content type of the input
+const InputContentTypeKey = "__input_content_type"
+
+// OutputContentTypeVar is the reserved variable in which SetOutputWithType
+// records the content type of the output for the host to read
+const OutputContentTypeVar = "__output_content_type"
+
+// SetOutputWithType sets the output and records its content type, such as
+// "application/json", in the OutputContentTypeVar variable. Extism output is
+// raw bytes, so this is a convention: the host must read the variable after
+// the call to learn the type.
+func (h Host) SetOutputWithType(data []byte, contentType string) error {
+	if !h.SetVar(OutputContentTypeVar, contentType) {
+		return fmt.Errorf("%w %q", ErrVarSetFailed, OutputContentTypeVar)
+	}
+	return h.SetOutput(data)
+}
+
+// InputDecoder decodes the plugin input into v
+type InputDecoder func(h Host, v interface{}) error
+
+// inputDecoders maps lowercase media types to the decoders DecodeInput uses
+// for them
+var inputDecoders = map[string]InputDecoder{
+	"application/json": Host.GetInputJSON,
+	"text/json":        Host.GetInputJSON,
+	"application/xml":  Host.GetInputXML,
+	"text/xml":         Host.GetInputXML,
+}
+
+// RegisterInputDecoder registers the decoder DecodeInput uses for inputs of
+// the given media type, replacing any existing one. The msgpack and cbor
+// subpackages register their formats this way when imported.
+func RegisterInputDecoder(mediaType string, fn InputDecoder) {
+	inputDecoders[strings.ToLower(mediaType)] = fn
+}
+
+// InputContentType returns the content type of the input as reported by
+// the host in the InputContentTypeKey variable or, failing that,
+// configuration key, or "" if neither is set
+func (h Host) InputContentType() string {
+	if value, ok := h.lookupVar(InputContentTypeKey); ok {
+		return string(value)
+	}
+	value, _ := h.lookupConfig(InputContentTypeKey)
+	return value
+}
+
+// DecodeInput decodes the input into v with the decoder for its content
+// type (see InputContentType), defaulting to JSON when none is set.
+// Parameters such as charset are ignored, and media types with a +json or
+// +xml suffix use the JSON or XML decoder. An unsupported content type
+// returns an error.
+func (h Host) DecodeInput(v interface{}) error {
+	contentType := h.InputContentType()
+	if contentType == "" {
+		return h.GetInputJSON(v)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("invalid input content type %q: %w", contentType, err)
+	}
+
+	fn, ok := inputDecoders[mediaType]
+	switch {
+	case ok:
+	case strings.HasSuffix(mediaType, "+json"):
+		fn = Host.GetInputJSON
+	case strings.HasSuffix(mediaType, "+xml"):
+		fn = Host.GetInputXML
+	default:
+		return fmt.Errorf("unsupported input content type %q", contentType)
+	}
+	return fn(h, v)
+}
+
+// AcceptKey is the reserved variable or configuration key from which
+// RespondNegotiated reads the output formats the caller accepts, in the
+// form of an HTTP Accept header
+const AcceptKey = "__accept"
+
+// OutputEncoder encodes v and sets it as the plugin output
+type OutputEncoder func(h Host, v interface{}) error
+
+// outputEncoders maps lowercase media types to the encoders
+// RespondNegotiated uses for them
+var outputEncoders = map[string]OutputEncoder{
+	"application/json": Host.SetOutputJSON,
+	"text/json":        Host.SetOutputJSON,
+	"application/xml":  Host.SetOutputXML,
+	"text/xml":         Host.SetOutputXML,
+	"text/csv":         Host.setOutputCSV,
+}
+
+// RegisterOutputEncoder registers the encoder RespondNegotiated uses for the
+// given media type, replacing any existing one. The msgpack and cbor
+// subpackages register their formats this way when imported.
+func RegisterOutputEncoder(mediaType string, fn OutputEncoder) {
+	outputEncoders[strings.ToLower(mediaType)] = fn
+}
+
+// RespondNegotiated encodes v as output in the first format listed in the
+// AcceptKey variable or configuration key that has an encoder, and records
+// the chosen media type in OutputContentTypeVar. Without an AcceptKey, or
+// for */*, the output is JSON. Quality values are ignored; formats are tried
+// in the order listed, skipping text/csv when v isn't a slice it can encode.
+// If none is supported an error is returned and no output is set.
+func (h Host) RespondNegotiated(v interface{}) error {
+	accept, ok := h.lookupVar(AcceptKey)
+	if !ok {
+		value, _ := h.lookupConfig(AcceptKey)
+		accept = []byte(value)
+	}
+	if strings.TrimSpace(string(accept)) == "" {
+		accept = []byte("application/json")
+	}
+
+	for _, entry := range strings.Split(string(accept), ",") {
+		mediaType, _, err := mime.ParseMediaType(entry)
+		if err != nil {
+			continue
+		}
+		if mediaType == "*/*" || mediaType == "application/*" {
+			mediaType = "application/json"
+		}
+
+		fn, ok := outputEncoders[mediaType]
+		if !ok {
+			continue
+		}
+		if err := fn(h, v); err != nil {
+			if errors.Is(err, errNotCSV) {
+				continue
+			}
+			return err
+		}
+		if !h.SetVar(OutputContentTypeVar, mediaType) {
+			return fmt.Errorf("%w %q", ErrVarSetFailed, OutputContentTypeVar)
+		}
+		return nil
+	}
+	return fmt.Errorf("no supported output format in %q", accept)
+}
@@ -0,0 +1,94 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+type city struct {
+	Name       string `json:"name" xml:"name"`
+	Population int    `json:"population" xml:"population"`
+}
+
+var cities = []city{{Name: "Oslo", Population: 709000}, {Name: "Bergen", Population: 286000}}
+
+func TestRespondNegotiated(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+		output      string
+	}{
+		{
+			accept:      "",
+			contentType: "application/json",
+			output:      `[{"name":"Oslo","population":709000},{"name":"Bergen","population":286000}]`,
+		},
+		{
+			accept:      "*/*",
+			contentType: "application/json",
+			output:      `[{"name":"Oslo","population":709000},{"name":"Bergen","population":286000}]`,
+		},
+		{
+			accept:      "application/xml",
+			contentType: "application/xml",
+			output:      `<city><name>Oslo</name><population>709000</population></city><city><name>Bergen</name><population>286000</population></city>`,
+		},
+		{
+			accept:      "text/csv;q=0.9, application/json",
+			contentType: "text/csv",
+			output:      "name,population\nOslo,709000\nBergen,286000\n",
+		},
+		{
+			accept:      "image/png, application/json",
+			contentType: "application/json",
+			output:      `[{"name":"Oslo","population":709000},{"name":"Bergen","population":286000}]`,
+		},
+	}
+	for _, test := range tests {
+		m := &testhost.MockHost{Config: map[string]string{extism_pdk.AcceptKey: test.accept}}
+		if err := m.Host().RespondNegotiated(cities); err != nil {
+			t.Errorf("%q: %v", test.accept, err)
+			continue
+		}
+		if string(m.Output) != test.output {
+			t.Errorf("%q: Output = %q, want %q", test.accept, m.Output, test.output)
+		}
+		if got := m.Vars[extism_pdk.OutputContentTypeVar]; got != test.contentType {
+			t.Errorf("%q: content type = %q, want %q", test.accept, got, test.contentType)
+		}
+	}
+}
+
+func TestRespondNegotiatedUnsupported(t *testing.T) {
+	m := &testhost.MockHost{Vars: map[string]string{extism_pdk.AcceptKey: "image/png"}}
+	if err := m.Host().RespondNegotiated(cities); err == nil {
+		t.Fatal("no error for an unsupported format")
+	}
+	if m.Output != nil {
+		t.Fatalf("Output = %q, want none", m.Output)
+	}
+}
+
+func TestRespondNegotiatedSkipsCSVForNonSlice(t *testing.T) {
+	for _, v := range []interface{}{cities[0], []int{1, 2}} {
+		m := &testhost.MockHost{Vars: map[string]string{extism_pdk.AcceptKey: "text/csv, application/json"}}
+		if err := m.Host().RespondNegotiated(v); err != nil {
+			t.Fatalf("%T: %v", v, err)
+		}
+		if got := m.Vars[extism_pdk.OutputContentTypeVar]; got != "application/json" {
+			t.Errorf("%T: content type = %q, want application/json", v, got)
+		}
+	}
+
+	m := &testhost.MockHost{Vars: map[string]string{extism_pdk.AcceptKey: "text/csv"}}
+	if err := m.Host().RespondNegotiated(cities[0]); err == nil {
+		t.Fatal("no error when CSV is the only format and v isn't a slice")
+	}
+	if m.Output != nil {
+		t.Fatalf("Output = %q, want none", m.Output)
+	}
+}
@@ -0,0 +1,103 @@
+package extism_pdk
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// InputCSVReader returns a CSV reader that streams the input from the host
+func (h Host) InputCSVReader() *csv.Reader {
+	return csv.NewReader(h.InputReader())
+}
+
+// OutputCSVWriter returns a CSV writer for composing the output row by row,
+// along with a function that flushes the writer and sets the output. The
+// output is only set when that function is called, and it can only be
+// called once.
+func (h Host) OutputCSVWriter() (*csv.Writer, func() error) {
+	w := h.OutputWriter()
+	cw := csv.NewWriter(w)
+	return cw, func() error {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+		return w.Close()
+	}
+}
+
+// errNotCSV is returned by setOutputCSV for a value it can't encode, so
+// that RespondNegotiated moves on to the next accepted format
+var errNotCSV = errors.New("CSV output requires a slice of structs or [][]string")
+
+// csvStructType returns the element struct type of v if it is a slice of
+// structs or struct pointers
+func csvStructType(v interface{}) (reflect.Type, bool) {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Slice {
+		return nil, false
+	}
+	elem := value.Type().Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	return elem, elem.Kind() == reflect.Struct
+}
+
+// setOutputCSV sets a slice as CSV output for RespondNegotiated. A [][]string
+// is written as rows; a slice of structs gets a header row of field names
+// (using the json tag name when present) followed by one row per element.
+// Any other value is rejected with errNotCSV before any output is written.
+func (h Host) setOutputCSV(v interface{}) error {
+	if rows, ok := v.([][]string); ok {
+		cw, commit := h.OutputCSVWriter()
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return commit()
+	}
+
+	elem, ok := csvStructType(v)
+	if !ok {
+		return fmt.Errorf("%w, got %T", errNotCSV, v)
+	}
+	value := reflect.ValueOf(v)
+	cw, commit := h.OutputCSVWriter()
+
+	var fields []int
+	var header []string
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, i)
+		header = append(header, name)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	row := make([]string, len(fields))
+	for i := 0; i < value.Len(); i++ {
+		item := reflect.Indirect(value.Index(i))
+		for j, field := range fields {
+			if item.IsValid() {
+				row[j] = fmt.Sprint(item.Field(field).Interface())
+			} else {
+				row[j] = ""
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	return commit()
+}
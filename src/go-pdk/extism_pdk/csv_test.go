@@ -0,0 +1,53 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+func TestCSVQuotedFieldsRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"name", "note"},
+		{"Ada", `said "hi"`},
+		{"Grace, H.", "line one\nline two"},
+	}
+
+	m := &testhost.MockHost{}
+	h := m.Host()
+	cw, commit := h.OutputCSVWriter()
+	if err := cw.WriteAll(rows); err != nil {
+		t.Fatal(err)
+	}
+	if m.Output != nil {
+		t.Fatal("output set before commit")
+	}
+	if err := commit(); err != nil {
+		t.Fatal(err)
+	}
+	want := "name,note\nAda,\"said \"\"hi\"\"\"\n\"Grace, H.\",\"line one\nline two\"\n"
+	if string(m.Output) != want {
+		t.Fatalf("Output = %q, want %q", m.Output, want)
+	}
+
+	m.Input = m.Output
+	r := h.InputCSVReader()
+	var got [][]string
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, record)
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Fatalf("read %q, want %q", got, rows)
+	}
+}
@@ -0,0 +1,64 @@
+package extism_pdk
+
+import "fmt"
+
+// handlers maps the names passed to Register to their handlers
+var handlers = map[string]func(Host) int32{}
+
+// FunctionNameKey is the reserved variable or configuration key in which the
+// host can name the exported function being invoked
+const FunctionNameKey = "__function_name"
+
+// currentFunction is the name of the handler Dispatch is running
+var currentFunction string
+
+// FunctionName returns the name of the exported function being invoked: the
+// name passed to Dispatch while a dispatched handler runs, otherwise the
+// FunctionNameKey variable or, failing that, configuration key, or "" if
+// none of them is set
+func (h Host) FunctionName() string {
+	if currentFunction != "" {
+		return currentFunction
+	}
+	if value, ok := h.lookupVar(FunctionNameKey); ok {
+		return string(value)
+	}
+	value, _ := h.lookupConfig(FunctionNameKey)
+	return value
+}
+
+// Register registers a handler to be run by Dispatch under name. Call it
+// from an init function.
+func Register(name string, fn func(Host) int32) {
+	handlers[name] = fn
+}
+
+// Dispatch runs the handler registered under name. WASM exports can't be
+// created at runtime, so each handler still needs an exported stub that
+// calls Dispatch:
+//
+//	func init() {
+//		extism_pdk.Register("greet", greet)
+//	}
+//
+//	//export greet
+//	func greetExport() int32 {
+//		return extism_pdk.Dispatch("greet")
+//	}
+//
+// Panics in the handler are converted into plugin errors as with Guard, and
+// an unregistered name sets the plugin error and returns 1. While the handler
+// runs, FunctionName returns name.
+func Dispatch(name string) int32 {
+	fn, ok := handlers[name]
+	if !ok {
+		CreateHost().SetError(fmt.Sprintf("no handler registered for %q", name))
+		return 1
+	}
+
+	currentFunction = name
+	defer func() { currentFunction = "" }()
+	return Guard(func() int32 {
+		return fn(CreateHost())
+	})
+}
@@ -0,0 +1,50 @@
+package extism_pdk
+
+import "errors"
+
+// Errors returned by the PDK for common failure modes. They may be wrapped
+// with more context, so compare them with errors.Is.
+var (
+	// ErrNoInput is returned when decoding an empty input
+	ErrNoInput = errors.New("no input")
+
+	// ErrInputTooLarge is returned by GetInputChecked when the input exceeds
+	// the limit set with SetMaxInputSize
+	ErrInputTooLarge = errors.New("input too large")
+
+	// ErrAllocFailed is returned when the host cannot allocate memory
+	ErrAllocFailed = errors.New("host memory allocation failed")
+
+	// ErrOutOfBounds is returned when the host reports a memory range that
+	// overflows or can't be addressed by the plugin
+	ErrOutOfBounds = errors.New("host memory range out of bounds")
+
+	// ErrHTTPFailed is returned when the host fails to make an HTTP request
+	ErrHTTPFailed = errors.New("HTTP request failed")
+
+	// ErrHTTPBlocked is matched by an HTTP failure the host attributes to
+	// its policy, such as a URL outside the allowed hosts
+	ErrHTTPBlocked = errors.New("HTTP request blocked by host policy")
+
+	// ErrHTTPTimeout is matched by an HTTP failure the host attributes to a
+	// timeout
+	ErrHTTPTimeout = errors.New("HTTP request timed out")
+
+	// ErrConfigMissing is returned when a required configuration key is
+	// absent
+	ErrConfigMissing = errors.New("config key not found")
+
+	// ErrVarMissing is returned when a required variable is absent
+	ErrVarMissing = errors.New("var not found")
+
+	// ErrVarSetFailed is returned when the host fails to store a variable
+	ErrVarSetFailed = errors.New("failed to set var")
+
+	// ErrHostFuncNotFound is returned when calling a host function that has
+	// not been registered
+	ErrHostFuncNotFound = errors.New("host function not registered")
+
+	// ErrWriterClosed is returned when writing to or closing an output
+	// writer that has already been closed
+	ErrWriterClosed = errors.New("output writer already closed")
+)
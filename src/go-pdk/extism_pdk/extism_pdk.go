@@ -2,74 +2,13 @@
 package extism_pdk
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 )
 
-// Memory operations - these are imported from the host environment
-//
-//export extism_input_length
-func extism_input_length() uint64
-
-//export extism_input_load
-func extism_input_load(offset uint64, length uint64) uint64
-
-//export extism_output_set
-func extism_output_set(offset uint64, length uint64) uint64
-
-//export extism_error_set
-func extism_error_set(offset uint64, length uint64) uint64
-
-//export extism_length
-func extism_length(id uint64) uint64
-
-//export extism_alloc
-func extism_alloc(length uint64) uint64
-
-//export extism_free
-func extism_free(offset uint64)
-
-//export extism_store_u8
-func extism_store_u8(offset uint64, value uint8)
-
-//export extism_store_u64
-func extism_store_u64(offset uint64, value uint64)
-
-//export extism_load_u8
-func extism_load_u8(offset uint64) uint8
-
-//export extism_load_u64
-func extism_load_u64(offset uint64) uint64
-
-// Host functions - these are functions provided by the host
-//
-//export extism_http_request
-func extism_http_request(request uint64, request_length uint64) uint64
-
-//export extism_http_status_code
-func extism_http_status_code() uint64
-
-//export extism_config_get
-func extism_config_get(key uint64, key_length uint64) uint64
-
-//export extism_var_get
-func extism_var_get(key uint64, key_length uint64) uint64
-
-//export extism_var_set
-func extism_var_set(key uint64, key_length uint64, value uint64, value_length uint64) uint64
-
-//export extism_log_info
-func extism_log_info(msg uint64, msg_length uint64)
-
-//export extism_log_debug
-func extism_log_debug(msg uint64, msg_length uint64)
-
-//export extism_log_warn
-func extism_log_warn(msg uint64, msg_length uint64)
-
-//export extism_log_error
-func extism_log_error(msg uint64, msg_length uint64)
-
 // Host is the main interface for interacting with the host environment
 type Host struct{}
 
@@ -80,15 +19,31 @@ func (h Host) GetInput() []byte {
 		return []byte{}
 	}
 
-	memory := make([]byte, length)
 	ptr := extism_input_load(0, length)
+	return Memory{offset: ptr, length: length}.Load()
+}
 
-	// Copy from host memory to Go slice
-	for i := uint64(0); i < length; i++ {
-		memory[i] = extism_load_u8(ptr + i)
+// GetInputInto copies the input into buf without allocating and returns the
+// number of bytes copied. If the input does not fit, buf is filled and
+// io.ErrShortBuffer is returned.
+func (h Host) GetInputInto(buf []byte) (int, error) {
+	length := extism_input_length()
+	if length == 0 {
+		return 0, nil
 	}
 
-	return memory
+	n := length
+	if n > uint64(len(buf)) {
+		n = uint64(len(buf))
+	}
+
+	ptr := extism_input_load(0, length)
+	readMemoryInto(ptr, buf[:n])
+
+	if n < length {
+		return int(n), io.ErrShortBuffer
+	}
+	return int(n), nil
 }
 
 // GetInputString returns the input data as a string
@@ -96,24 +51,61 @@ func (h Host) GetInputString() string {
 	return string(h.GetInput())
 }
 
-// GetInputJSON unmarshals the input JSON into the provided interface
+// GetInputJSON unmarshals the input JSON into the provided interface,
+// returning ErrNoInput if the input is empty
 func (h Host) GetInputJSON(v interface{}) error {
 	data := h.GetInput()
+	if len(data) == 0 {
+		return ErrNoInput
+	}
 	return json.Unmarshal(data, v)
 }
 
-// SetOutput sets the output data for the plugin
+// GetInputJSONStrict decodes the input JSON into the provided interface,
+// streaming it from the host and rejecting fields that v does not define.
+// Errors include the input offset at which decoding stopped, and an empty
+// input returns ErrNoInput.
+func (h Host) GetInputJSONStrict(v interface{}) error {
+	if h.InputLength() == 0 {
+		return ErrNoInput
+	}
+
+	dec := json.NewDecoder(h.InputReader())
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid input JSON at offset %d: %w", dec.InputOffset(), err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("invalid input JSON at offset %d: unexpected data after value", dec.InputOffset())
+	}
+	return nil
+}
+
+// SetOutput sets the output data for the plugin. Ownership of the output
+// buffer passes to the host, which releases it once the call completes, so it
+// must not be freed here. Empty data is set as with SetOutputEmpty.
 func (h Host) SetOutput(data []byte) error {
-	length := uint64(len(data))
-	ptr := extism_alloc(length)
+	if len(data) == 0 {
+		return h.SetOutputEmpty()
+	}
 
-	// Copy from Go slice to host memory
-	for i := uint64(0); i < length; i++ {
-		extism_store_u8(ptr+i, data[i])
+	mem, err := allocateMemory(data)
+	if err != nil {
+		return err
 	}
+	flushLogs()
+	extism_output_set(mem.Offset(), mem.Length())
+	return nil
+}
 
-	extism_output_set(ptr, length)
-	extism_free(ptr)
+// SetOutputEmpty sets a zero-length output, for functions that succeed
+// without producing anything. No host memory is allocated: the output is set
+// with offset and length 0. The host sees an output that was set but is
+// empty, which it may treat the same as no output.
+func (h Host) SetOutputEmpty() error {
+	flushLogs()
+	extism_output_set(0, 0)
 	return nil
 }
 
@@ -131,223 +123,279 @@ func (h Host) SetOutputJSON(v interface{}) error {
 	return h.SetOutput(data)
 }
 
-// SetError sets an error message for the plugin
-func (h Host) SetError(msg string) error {
-	data := []byte(msg)
-	length := uint64(len(data))
-	ptr := extism_alloc(length)
+// JSONOptions configures the JSON encoding of SetOutputJSONWith
+type JSONOptions struct {
+	// EscapeHTML escapes <, > and & in strings, as json.Marshal does. The
+	// zero value leaves them as is.
+	EscapeHTML bool
+	// Prefix and Indent, if either is set, indent the output as
+	// json.MarshalIndent does
+	Prefix string
+	Indent string
+}
 
-	// Copy from Go slice to host memory
-	for i := uint64(0); i < length; i++ {
-		extism_store_u8(ptr+i, data[i])
+// SetOutputJSONWith encodes the provided interface as JSON with the given
+// options and sets it as output. Unlike json.Encoder, it does not add a
+// trailing newline. SetOutputJSON is equivalent to passing
+// JSONOptions{EscapeHTML: true}.
+func (h Host) SetOutputJSONWith(v interface{}, opts JSONOptions) error {
+	w := &outputWriter{host: h}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(opts.EscapeHTML)
+	if opts.Prefix != "" || opts.Indent != "" {
+		enc.SetIndent(opts.Prefix, opts.Indent)
+	}
+	if err := enc.Encode(v); err != nil {
+		return err
 	}
 
-	extism_error_set(ptr, length)
-	extism_free(ptr)
-	return nil
+	w.buf.Truncate(w.buf.Len() - 1)
+	return w.Close()
 }
 
-// LogInfo logs an informational message
-func (h Host) LogInfo(msg string) {
-	data := []byte(msg)
-	length := uint64(len(data))
-	ptr := extism_alloc(length)
+// SetError sets an error message for the plugin and returns it as an error
+// so that it can be propagated. If the message can't be copied into host
+// memory, the allocation error is returned instead. As with SetOutput, the
+// host takes ownership of the message buffer.
+//
+// An empty message allocates nothing: the error is set with offset and
+// length 0, which Extism treats as clearing it, and nil is returned. Buffered
+// log messages are flushed first, so they reach the host on failure paths.
+func (h Host) SetError(msg string) error {
+	flushLogs()
+	if msg == "" {
+		extism_error_set(0, 0)
+		return nil
+	}
 
-	// Copy from Go slice to host memory
-	for i := uint64(0); i < length; i++ {
-		extism_store_u8(ptr+i, data[i])
+	mem, err := allocateMemory([]byte(msg))
+	if err != nil {
+		return err
 	}
+	extism_error_set(mem.Offset(), mem.Length())
+	return errors.New(msg)
+}
 
-	extism_log_info(ptr, length)
-	extism_free(ptr)
+// SetErrorf sets a formatted error message for the plugin and returns it as
+// an error, like SetError
+func (h Host) SetErrorf(format string, args ...interface{}) error {
+	return h.SetError(sprintf(format, args))
 }
 
-// LogDebug logs a debug message
-func (h Host) LogDebug(msg string) {
-	data := []byte(msg)
-	length := uint64(len(data))
-	ptr := extism_alloc(length)
+// outputError is the error object set as output by SetOutputError
+type outputError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
 
-	// Copy from Go slice to host memory
-	for i := uint64(0); i < length; i++ {
-		extism_store_u8(ptr+i, data[i])
-	}
+// SetOutputError sets an application-level error as the output, as
+// {"error":{"code":code,"message":message}}. The call still succeeds: use it
+// for errors the caller is expected to handle, such as invalid arguments,
+// and SetError or Fail when the plugin itself failed and the call should.
+func (h Host) SetOutputError(code string, message string) error {
+	var out outputError
+	out.Error.Code = code
+	out.Error.Message = message
+	return h.SetOutputJSON(out)
+}
 
-	extism_log_debug(ptr, length)
-	extism_free(ptr)
+// Fail logs msg as an error, sets it as the plugin error and returns 1, the
+// conventional failure code, so an entrypoint can exit with
+// return host.Fail("...")
+func (h Host) Fail(msg string) int32 {
+	h.LogError(msg)
+	h.SetError(msg)
+	return 1
 }
 
-// LogWarn logs a warning message
-func (h Host) LogWarn(msg string) {
-	data := []byte(msg)
-	length := uint64(len(data))
-	ptr := extism_alloc(length)
+// Failf formats a message and fails with it, like Fail
+func (h Host) Failf(format string, args ...interface{}) int32 {
+	return h.Fail(sprintf(format, args))
+}
 
-	// Copy from Go slice to host memory
-	for i := uint64(0); i < length; i++ {
-		extism_store_u8(ptr+i, data[i])
-	}
+// LogInfo logs an informational message
+func (h Host) LogInfo(msg string) {
+	h.log(LogLevelInfo, msg)
+}
 
-	extism_log_warn(ptr, length)
-	extism_free(ptr)
+// LogDebug logs a debug message
+func (h Host) LogDebug(msg string) {
+	h.log(LogLevelDebug, msg)
+}
+
+// LogWarn logs a warning message
+func (h Host) LogWarn(msg string) {
+	h.log(LogLevelWarn, msg)
 }
 
 // LogError logs an error message
 func (h Host) LogError(msg string) {
-	data := []byte(msg)
-	length := uint64(len(data))
-	ptr := extism_alloc(length)
-
-	// Copy from Go slice to host memory
-	for i := uint64(0); i < length; i++ {
-		extism_store_u8(ptr+i, data[i])
-	}
-
-	extism_log_error(ptr, length)
-	extism_free(ptr)
+	h.log(LogLevelError, msg)
 }
 
-// HTTPRequest makes an HTTP request to the host
+// HTTPRequest makes an HTTP request to the host. A binary body can be sent
+// with BodyBytes, which is base64-encoded for the host and takes precedence
+// over Body when both are set. TimeoutMs is a hint that hosts may use to
+// bound the request; 0 leaves the timeout to the host. StreamBody asks the
+// host to return the response body as a handle to host memory rather than
+// inline (see HTTPResponse.BodyReader).
 type HTTPRequest struct {
-	Method  string            `json:"method"`
-	URL     string            `json:"url"`
-	Headers map[string]string `json:"headers,omitempty"`
-	Body    string            `json:"body,omitempty"`
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body,omitempty"`
+	BodyBytes  []byte            `json:"body_base64,omitempty"`
+	TimeoutMs  int               `json:"timeout_ms,omitempty"`
+	StreamBody bool              `json:"stream_body,omitempty"`
 }
 
-// HTTPResponse is the response from an HTTP request
+// HTTPResponse is the response from an HTTP request. Headers holds one value
+// per header; when the host reports repeated headers, HeadersMulti holds all
+// of them. Body is empty when the host returned the body as a handle; use
+// BodyBytes or BodyReader, which work either way.
 type HTTPResponse struct {
-	Status  int               `json:"status"`
-	Headers map[string]string `json:"headers,omitempty"`
-	Body    string            `json:"body"`
+	Status       int                 `json:"status"`
+	StatusText   string              `json:"status_text,omitempty"`
+	Headers      map[string]string   `json:"headers,omitempty"`
+	HeadersMulti map[string][]string `json:"headers_multi,omitempty"`
+	Body         string              `json:"body"`
+	// Redirects lists the URLs HTTPFollow was redirected to, in order
+	Redirects []string `json:"-"`
+
+	body []byte
+	// bodyHandle is the host memory block holding the body, when the host
+	// returned it as a handle
+	bodyHandle Memory
 }
 
-// HTTP makes an HTTP request
-func (h Host) HTTP(req HTTPRequest) (*HTTPResponse, error) {
-	data, err := json.Marshal(req)
-	if err != nil {
-		return nil, err
+// BodyBytes returns the response body as bytes. Binary bodies sent by the
+// host base64-encoded are returned decoded, and a body returned as a handle
+// is loaded from host memory on first use.
+func (r *HTTPResponse) BodyBytes() []byte {
+	if r.body == nil && r.bodyHandle.offset != 0 {
+		r.body = r.bodyHandle.Load()
 	}
-
-	length := uint64(len(data))
-	ptr := extism_alloc(length)
-
-	// Copy from Go slice to host memory
-	for i := uint64(0); i < length; i++ {
-		extism_store_u8(ptr+i, data[i])
+	if r.body != nil {
+		return r.body
 	}
+	return []byte(r.Body)
+}
 
-	resultPtr := extism_http_request(ptr, length)
-	extism_free(ptr)
-
-	if resultPtr == 0 {
-		return nil, fmt.Errorf("HTTP request failed")
+// BodyReader returns a reader over the response body. If the request set
+// StreamBody and the host returned the body as a handle, the reader loads
+// it from host memory as it is read instead of copying it all at once.
+//
+// The handle refers to host memory that is only valid during the current
+// plugin call and until Close is called, so the body must be read before
+// then; don't keep the reader or response across calls.
+func (r *HTTPResponse) BodyReader() io.Reader {
+	if r.body == nil && r.bodyHandle.offset != 0 {
+		return &memoryReader{mem: r.bodyHandle}
 	}
+	return bytes.NewReader(r.BodyBytes())
+}
 
-	resultLength := extism_length(resultPtr)
-	result := make([]byte, resultLength)
+// Close frees the host memory holding a body returned as a handle. The body
+// can't be read afterwards unless it was already loaded with BodyBytes.
+// Closing a response without a handle does nothing.
+func (r *HTTPResponse) Close() {
+	r.bodyHandle.Free()
+	r.bodyHandle = Memory{}
+}
 
-	// Copy from host memory to Go slice
-	for i := uint64(0); i < resultLength; i++ {
-		result[i] = extism_load_u8(resultPtr + i)
+// HTTP makes an HTTP request. The method is uppercased, defaulting to GET
+// when empty, and a method that isn't a valid HTTP token is rejected before
+// the request reaches the host.
+func (h Host) HTTP(req HTTPRequest) (*HTTPResponse, error) {
+	method, err := normalizeMethod(req.Method)
+	if err != nil {
+		return nil, err
 	}
+	req.Method = method
 
-	status := extism_http_status_code()
+	if req.BodyBytes != nil {
+		req.Body = ""
+	}
 
-	var response HTTPResponse
-	err = json.Unmarshal(result, &response)
+	data, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
 
-	response.Status = int(status)
-	return &response, nil
-}
-
-// GetConfig gets a configuration value by key
-func (h Host) GetConfig(key string) string {
-	data := []byte(key)
-	length := uint64(len(data))
-	ptr := extism_alloc(length)
-
-	// Copy from Go slice to host memory
-	for i := uint64(0); i < length; i++ {
-		extism_store_u8(ptr+i, data[i])
+	mem, err := allocateMemory(data)
+	if err != nil {
+		return nil, err
 	}
+	defer mem.Free()
 
-	resultPtr := extism_config_get(ptr, length)
-	extism_free(ptr)
+	return h.sendHTTP(mem)
+}
 
+// sendHTTP sends an HTTP request already encoded as JSON in host memory and
+// decodes the host's response
+func (h Host) sendHTTP(mem Memory) (*HTTPResponse, error) {
+	resultPtr := extism_http_request(mem.Offset(), mem.Length())
 	if resultPtr == 0 {
-		return ""
+		return nil, httpFailure()
 	}
 
-	resultLength := extism_length(resultPtr)
-	result := make([]byte, resultLength)
+	result := findMemory(resultPtr).Load()
 
-	// Copy from host memory to Go slice
-	for i := uint64(0); i < resultLength; i++ {
-		result[i] = extism_load_u8(resultPtr + i)
-	}
-
-	return string(result)
-}
-
-// GetVar gets a variable value by key
-func (h Host) GetVar(key string) string {
-	data := []byte(key)
-	length := uint64(len(data))
-	ptr := extism_alloc(length)
+	status := extism_http_status_code()
 
-	// Copy from Go slice to host memory
-	for i := uint64(0); i < length; i++ {
-		extism_store_u8(ptr+i, data[i])
+	var wire struct {
+		HTTPResponse
+		BodyBase64 []byte `json:"body_base64"`
+		BodyHandle uint64 `json:"body_handle"`
 	}
-
-	resultPtr := extism_var_get(ptr, length)
-	extism_free(ptr)
-
-	if resultPtr == 0 {
-		return ""
+	if err := json.Unmarshal(result, &wire); err != nil {
+		return nil, err
 	}
 
-	resultLength := extism_length(resultPtr)
-	result := make([]byte, resultLength)
-
-	// Copy from host memory to Go slice
-	for i := uint64(0); i < resultLength; i++ {
-		result[i] = extism_load_u8(resultPtr + i)
+	response := wire.HTTPResponse
+	if wire.BodyBase64 != nil {
+		response.body = wire.BodyBase64
+		response.Body = string(wire.BodyBase64)
 	}
-
-	return string(result)
-}
-
-// SetVar sets a variable value by key
-func (h Host) SetVar(key string, value string) bool {
-	keyData := []byte(key)
-	keyLength := uint64(len(keyData))
-	keyPtr := extism_alloc(keyLength)
-
-	// Copy key from Go slice to host memory
-	for i := uint64(0); i < keyLength; i++ {
-		extism_store_u8(keyPtr+i, keyData[i])
+	if wire.BodyHandle != 0 {
+		response.bodyHandle = findMemory(wire.BodyHandle)
 	}
 
-	valueData := []byte(value)
-	valueLength := uint64(len(valueData))
-	valuePtr := extism_alloc(valueLength)
+	// Keep Headers complete for hosts that only send multi-value headers
+	for key, values := range response.HeadersMulti {
+		if _, ok := response.Headers[key]; ok || len(values) == 0 {
+			continue
+		}
+		if response.Headers == nil {
+			response.Headers = map[string]string{}
+		}
+		response.Headers[key] = values[0]
+	}
 
-	// Copy value from Go slice to host memory
-	for i := uint64(0); i < valueLength; i++ {
-		extism_store_u8(valuePtr+i, valueData[i])
+	response.Status = int(status)
+	if response.StatusText == "" {
+		response.StatusText = statusText[response.Status]
 	}
+	return &response, nil
+}
 
-	result := extism_var_set(keyPtr, keyLength, valuePtr, valueLength)
+// GetConfig gets a configuration value by key
+func (h Host) GetConfig(key string) string {
+	value, _ := h.lookupConfig(key)
+	return value
+}
 
-	extism_free(keyPtr)
-	extism_free(valuePtr)
+// GetVar gets a variable value by key
+func (h Host) GetVar(key string) string {
+	value, _ := h.lookupVar(key)
+	return string(value)
+}
 
-	return result == 1
+// SetVar sets a variable value by key
+func (h Host) SetVar(key string, value string) bool {
+	return h.SetVarBytes(key, []byte(value))
 }
 
 // CreateHost creates a new Host instance
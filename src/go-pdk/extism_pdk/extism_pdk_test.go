@@ -0,0 +1,121 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+func TestGetInputJSONStrict(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{input: `{"a":1}`},
+		{input: " {\"a\":1}\n"},
+		{input: `{"a":1,"b":2}`, err: `unknown field "b"`},
+		{input: `{"a":1}}`, err: "after value"},
+		{input: `{"a":1}]`, err: "after value"},
+		{input: `{"a":1} {"a":2}`, err: "after value"},
+		{input: `{"a":`, err: "invalid input JSON at offset"},
+	}
+	for _, test := range tests {
+		m := &testhost.MockHost{Input: []byte(test.input)}
+		var v struct{ A int }
+		err := m.Host().GetInputJSONStrict(&v)
+		if test.err == "" {
+			if err != nil || v.A != 1 {
+				t.Errorf("%q: v = %+v, err = %v", test.input, v, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%q: err = %v, want %q", test.input, err, test.err)
+		}
+	}
+}
+
+func TestGetInputJSONStrictEmpty(t *testing.T) {
+	m := &testhost.MockHost{}
+	var v struct{}
+	if err := m.Host().GetInputJSONStrict(&v); !errors.Is(err, extism_pdk.ErrNoInput) {
+		t.Fatalf("err = %v, want ErrNoInput", err)
+	}
+}
+
+func TestSetOutputDoesNotFree(t *testing.T) {
+	setters := map[string]func(extism_pdk.Host) error{
+		"SetOutput":       func(h extism_pdk.Host) error { return h.SetOutput([]byte("out")) },
+		"SetOutputString": func(h extism_pdk.Host) error { return h.SetOutputString("out") },
+		"SetOutputJSON":   func(h extism_pdk.Host) error { return h.SetOutputJSON("out") },
+	}
+	for name, set := range setters {
+		m := &testhost.MockHost{}
+		if err := set(m.Host()); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(m.Output) == 0 {
+			t.Errorf("%s: no output set", name)
+		}
+		// The host owns the output buffer once it is set
+		if len(m.Frees) != 0 {
+			t.Errorf("%s: freed %v after handing it to the host", name, m.Frees)
+		}
+	}
+}
+
+func TestAllocFailure(t *testing.T) {
+	m := &testhost.MockHost{FailAlloc: true}
+	h := m.Host()
+
+	if err := h.SetOutput([]byte("out")); !errors.Is(err, extism_pdk.ErrAllocFailed) {
+		t.Errorf("SetOutput err = %v, want ErrAllocFailed", err)
+	}
+	if err := h.SetError("failed"); !errors.Is(err, extism_pdk.ErrAllocFailed) {
+		t.Errorf("SetError err = %v, want ErrAllocFailed", err)
+	}
+	h.LogInfo("dropped")
+	if m.Output != nil || m.Error != "" || len(m.CapturedLogs) != 0 {
+		t.Errorf("Output = %q, Error = %q, CapturedLogs = %v, want nothing set", m.Output, m.Error, m.CapturedLogs)
+	}
+}
+
+func TestSetOutputEmpty(t *testing.T) {
+	m := &testhost.MockHost{}
+	if err := m.Host().SetOutputEmpty(); err != nil {
+		t.Fatal(err)
+	}
+	if m.Output == nil || len(m.Output) != 0 {
+		t.Fatalf("Output = %#v, want set but empty", m.Output)
+	}
+	if m.Allocs != 0 {
+		t.Fatalf("Allocs = %d, want none", m.Allocs)
+	}
+}
+
+func TestEmptyOutputAndError(t *testing.T) {
+	m := &testhost.MockHost{}
+	h := m.Host()
+
+	if err := h.SetOutput([]byte{}); err != nil {
+		t.Fatal(err)
+	}
+	if m.Output == nil || len(m.Output) != 0 {
+		t.Fatalf("Output = %#v, want set but empty", m.Output)
+	}
+
+	h.SetError("failed")
+	allocs := m.Allocs
+	if err := h.SetError(""); err != nil {
+		t.Fatalf("SetError(\"\") = %v, want nil", err)
+	}
+	if m.Error != "" {
+		t.Fatalf("Error = %q, want cleared", m.Error)
+	}
+	if m.Allocs != allocs {
+		t.Fatalf("SetError(\"\") allocated %d blocks", m.Allocs-allocs)
+	}
+}
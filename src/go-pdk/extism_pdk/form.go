@@ -0,0 +1,11 @@
+package extism_pdk
+
+import "net/url"
+
+// InputForm parses the input as an application/x-www-form-urlencoded body,
+// the form PostForm sends. Malformed input returns the first parse error,
+// along with the values that could be parsed, as url.ParseQuery does. An
+// empty input is an empty form.
+func (h Host) InputForm() (url.Values, error) {
+	return url.ParseQuery(h.GetInputString())
+}
@@ -0,0 +1,36 @@
+package extism_pdk
+
+// Handle runs a typed handler: it unmarshals the JSON input into In, calls
+// fn, and marshals the result to the JSON output. If decoding, fn or encoding
+// fails, the error is set as the plugin error and 1 is returned. fn runs
+// as under Guard, so a panic is logged to h with its stack, h's error is set
+// to the panic value and 1 is returned, and buffered logs and the memory
+// pool are flushed before Handle returns.
+//
+//	//export greet
+//	func greet() int32 {
+//		return extism_pdk.Handle(extism_pdk.CreateHost(), func(req Request) (Response, error) {
+//			return Response{Greeting: "Hello, " + req.Name}, nil
+//		})
+//	}
+func Handle[In any, Out any](h HostAPI, fn func(In) (Out, error)) int32 {
+	return guard(h, func() int32 {
+		var in In
+		if err := h.GetInputJSON(&in); err != nil {
+			h.SetError("invalid input: " + err.Error())
+			return 1
+		}
+
+		out, err := fn(in)
+		if err != nil {
+			h.SetError(err.Error())
+			return 1
+		}
+
+		if err := h.SetOutputJSON(out); err != nil {
+			h.SetError("failed to set output: " + err.Error())
+			return 1
+		}
+		return 0
+	})
+}
@@ -0,0 +1,103 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+type greetRequest struct {
+	Name string `json:"name"`
+}
+
+type greetResponse struct {
+	Greeting string `json:"greeting"`
+}
+
+func greet(req greetRequest) (greetResponse, error) {
+	if req.Name == "" {
+		return greetResponse{}, errors.New("name is required")
+	}
+	return greetResponse{Greeting: "Hello, " + req.Name}, nil
+}
+
+func TestHandle(t *testing.T) {
+	m := &testhost.MockHost{Input: []byte(`{"name":"Ada"}`)}
+	if code := extism_pdk.Handle(m, greet); code != 0 {
+		t.Fatalf("code = %d, Error = %q", code, m.Error)
+	}
+	if got, want := string(m.Output), `{"greeting":"Hello, Ada"}`; got != want {
+		t.Fatalf("Output = %s, want %s", got, want)
+	}
+}
+
+func TestHandleError(t *testing.T) {
+	m := &testhost.MockHost{Input: []byte(`{}`)}
+	if code := extism_pdk.Handle(m, greet); code != 1 {
+		t.Fatalf("code = %d, want 1", code)
+	}
+	if m.Error != "name is required" {
+		t.Fatalf("Error = %q", m.Error)
+	}
+}
+
+func TestHandleInvalidInput(t *testing.T) {
+	m := &testhost.MockHost{Input: []byte(`{`)}
+	if code := extism_pdk.Handle(m, greet); code != 1 {
+		t.Fatalf("code = %d, want 1", code)
+	}
+	if !strings.HasPrefix(m.Error, "invalid input: ") {
+		t.Fatalf("Error = %q", m.Error)
+	}
+}
+
+func TestHandlePanic(t *testing.T) {
+	m := &testhost.MockHost{Input: []byte(`{}`)}
+	code := extism_pdk.Handle(m, func(greetRequest) (greetResponse, error) {
+		panic("boom")
+	})
+	if code != 1 {
+		t.Fatalf("code = %d, want 1", code)
+	}
+	if m.Error != "panic: boom" {
+		t.Fatalf("Error = %q", m.Error)
+	}
+	if len(m.CapturedLogs) != 1 || !strings.Contains(m.CapturedLogs[0].Message, "goroutine") {
+		t.Fatalf("stack not logged: %v", m.CapturedLogs)
+	}
+}
+
+// fakeHost is a HostAPI other than MockHost, recording the error and logs
+// a handler reports. Its other methods are left unimplemented.
+type fakeHost struct {
+	extism_pdk.HostAPI
+	err  string
+	logs []string
+}
+
+func (f *fakeHost) GetInputJSON(v interface{}) error { return nil }
+func (f *fakeHost) SetError(msg string) error        { f.err = msg; return nil }
+func (f *fakeHost) LogError(msg string)              { f.logs = append(f.logs, msg) }
+
+func TestHandlePanicReportedToHost(t *testing.T) {
+	// With no stub host installed, reaching the real host would panic
+	extism_pdk.UseStubHost(nil)
+	f := &fakeHost{}
+	code := extism_pdk.Handle(f, func(greetRequest) (greetResponse, error) {
+		panic("boom")
+	})
+	if code != 1 {
+		t.Fatalf("code = %d, want 1", code)
+	}
+	if f.err != "panic: boom" {
+		t.Fatalf("err = %q", f.err)
+	}
+	if len(f.logs) != 1 || !strings.Contains(f.logs[0], "goroutine") {
+		t.Fatalf("stack not logged: %v", f.logs)
+	}
+}
@@ -0,0 +1,32 @@
+package extism_pdk
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// InputSHA256 returns the SHA-256 digest of the input. The input is read
+// with InputBytesUnsafe, so hashing doesn't allocate a copy of it per call.
+func (h Host) InputSHA256() [32]byte {
+	return sha256.Sum256(h.InputBytesUnsafe())
+}
+
+// InputSHA256Hex returns the SHA-256 digest of the input as a lowercase hex
+// string
+func (h Host) InputSHA256Hex() string {
+	sum := h.InputSHA256()
+	return hex.EncodeToString(sum[:])
+}
+
+// InputMD5 returns the MD5 digest of the input. MD5 is not collision
+// resistant, so use it only for compatibility checksums, not security.
+func (h Host) InputMD5() [16]byte {
+	return md5.Sum(h.InputBytesUnsafe())
+}
+
+// InputMD5Hex returns the MD5 digest of the input as a lowercase hex string
+func (h Host) InputMD5Hex() string {
+	sum := h.InputMD5()
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,31 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+func TestInputHashes(t *testing.T) {
+	m := &testhost.MockHost{Input: []byte("abc")}
+	h := m.Host()
+
+	if got, want := h.InputSHA256Hex(), "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"; got != want {
+		t.Errorf("InputSHA256Hex = %s, want %s", got, want)
+	}
+	if got, want := h.InputMD5Hex(), "900150983cd24fb0d6963f7d28e17f72"; got != want {
+		t.Errorf("InputMD5Hex = %s, want %s", got, want)
+	}
+}
+
+// BenchmarkInputSHA256 should report the same B/op as
+// BenchmarkSHA256InputBytesUnsafe, not BenchmarkSHA256GetInput, showing the
+// input isn't copied into a fresh slice
+func BenchmarkInputSHA256(b *testing.B) {
+	benchmarkInput(b, func(h extism_pdk.Host) {
+		h.InputSHA256()
+	})
+}
@@ -0,0 +1,26 @@
+package extism_pdk
+
+// HostAPI is the set of host operations available to a plugin. Host
+// implements it against the Extism runtime; writing plugin logic against
+// HostAPI instead of Host lets it be unit-tested natively with
+// testhost.MockHost.
+type HostAPI interface {
+	GetInput() []byte
+	GetInputString() string
+	GetInputJSON(v interface{}) error
+	SetOutput(data []byte) error
+	SetOutputString(s string) error
+	SetOutputJSON(v interface{}) error
+	SetError(msg string) error
+	LogInfo(msg string)
+	LogDebug(msg string)
+	LogWarn(msg string)
+	LogError(msg string)
+	HTTP(req HTTPRequest) (*HTTPResponse, error)
+	GetConfig(key string) string
+	GetVar(key string) string
+	SetVar(key string, value string) bool
+	DeleteVar(key string) bool
+}
+
+var _ HostAPI = Host{}
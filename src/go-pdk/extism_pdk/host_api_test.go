@@ -0,0 +1,41 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+// countWords is plugin logic written against HostAPI, so it runs both in a
+// plugin and against a MockHost
+func countWords(host extism_pdk.HostAPI) int32 {
+	input := host.GetInputString()
+	count := 0
+	inWord := false
+	for _, r := range input {
+		if r == ' ' || r == '\n' {
+			inWord = false
+		} else if !inWord {
+			inWord = true
+			count++
+		}
+	}
+	host.LogDebug("counted words")
+	if err := host.SetOutputJSON(map[string]int{"count": count}); err != nil {
+		return 1
+	}
+	return 0
+}
+
+func TestHostAPIWithMockHost(t *testing.T) {
+	mock := &testhost.MockHost{Input: []byte("the quick brown fox")}
+	if code := countWords(mock); code != 0 {
+		t.Fatalf("code = %d", code)
+	}
+	if got, want := string(mock.Output), `{"count":4}`; got != want {
+		t.Fatalf("Output = %s, want %s", got, want)
+	}
+}
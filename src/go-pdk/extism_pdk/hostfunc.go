@@ -0,0 +1,51 @@
+package extism_pdk
+
+import "fmt"
+
+// HostFunc is a custom host function import. It takes a block of host memory
+// as input and returns the offset of a block holding its result, or 0 for no
+// result.
+type HostFunc func(offset uint64, length uint64) uint64
+
+// hostFuncs maps the names passed to RegisterHostFunc to their imports
+var hostFuncs = map[string]HostFunc{}
+
+// RegisterHostFunc registers a custom host function import under name so it
+// can be called with CallHostFunc. WASM imports are resolved when the module
+// is built, so the plugin declares the import itself and registers it:
+//
+//	//go:wasmimport env kv_get
+//	func kvGet(offset uint64, length uint64) uint64
+//
+//	func init() {
+//		extism_pdk.RegisterHostFunc("kv_get", kvGet)
+//	}
+func RegisterHostFunc(name string, fn HostFunc) {
+	hostFuncs[name] = fn
+}
+
+// CallHostFunc calls a registered host function, copying input into host
+// memory and the result back out, and freeing both blocks. A zero result
+// offset returns nil output.
+func CallHostFunc(name string, input []byte) ([]byte, error) {
+	fn, ok := hostFuncs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrHostFuncNotFound, name)
+	}
+
+	mem, err := allocateMemory(input)
+	if err != nil {
+		return nil, err
+	}
+	defer mem.Free()
+
+	resultPtr := fn(mem.Offset(), mem.Length())
+	if resultPtr == 0 {
+		return nil, nil
+	}
+
+	result := findMemory(resultPtr)
+	defer result.Free()
+
+	return result.Load(), nil
+}
@@ -0,0 +1,561 @@
+package extism_pdk
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxErrorBodyLength is the number of body bytes included in an
+// HTTPStatusError message
+const maxErrorBodyLength = 256
+
+// HTTPStatusError is returned by HTTPExpectOK when the response status is
+// outside the 2xx range
+type HTTPStatusError struct {
+	Status int
+	Body   string
+}
+
+// Error implements the error interface
+func (e *HTTPStatusError) Error() string {
+	body := e.Body
+	if len(body) > maxErrorBodyLength {
+		body = body[:maxErrorBodyLength] + "..."
+	}
+	return fmt.Sprintf("HTTP request returned status %d: %s", e.Status, body)
+}
+
+// HTTPErrorHostFunc is the name under which a host function reporting why
+// the last HTTP request failed can be registered with RegisterHostFunc. It
+// takes no input and returns either a plain message or a JSON object with
+// "kind" and "message" fields, where kind is "blocked", "timeout" or
+// "network".
+const HTTPErrorHostFunc = "extism_http_error"
+
+// HTTPHostError describes an HTTP request the host failed to make, as
+// reported by the HTTPErrorHostFunc host function. It matches ErrHTTPFailed
+// with errors.Is, and also ErrHTTPBlocked or ErrHTTPTimeout depending on
+// Kind.
+type HTTPHostError struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface
+func (e *HTTPHostError) Error() string {
+	if e.Kind == "" {
+		return fmt.Sprintf("%s: %s", ErrHTTPFailed, e.Message)
+	}
+	return fmt.Sprintf("%s (%s): %s", ErrHTTPFailed, e.Kind, e.Message)
+}
+
+// Is reports whether the error matches ErrHTTPBlocked or ErrHTTPTimeout
+func (e *HTTPHostError) Is(target error) bool {
+	switch target {
+	case ErrHTTPBlocked:
+		return e.Kind == "blocked"
+	case ErrHTTPTimeout:
+		return e.Kind == "timeout"
+	}
+	return false
+}
+
+// Unwrap returns ErrHTTPFailed
+func (e *HTTPHostError) Unwrap() error {
+	return ErrHTTPFailed
+}
+
+// httpFailure returns the error for an HTTP request the host failed to make,
+// with the host's reason if the plugin registered HTTPErrorHostFunc
+func httpFailure() error {
+	if _, ok := hostFuncs[HTTPErrorHostFunc]; !ok {
+		return fmt.Errorf("%w: host returned no response", ErrHTTPFailed)
+	}
+
+	detail, err := CallHostFunc(HTTPErrorHostFunc, nil)
+	if err != nil || len(detail) == 0 {
+		return fmt.Errorf("%w: host returned no response", ErrHTTPFailed)
+	}
+
+	var hostErr HTTPHostError
+	if json.Unmarshal(detail, &hostErr) != nil || hostErr.Message == "" {
+		hostErr = HTTPHostError{Message: string(detail)}
+	}
+	return &hostErr
+}
+
+// HTTPExpectOK makes an HTTP request like HTTP, but returns an
+// *HTTPStatusError along with the response if the status is not 2xx
+func (h Host) HTTPExpectOK(req HTTPRequest) (*HTTPResponse, error) {
+	resp, err := h.HTTP(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status < 200 || resp.Status > 299 {
+		return resp, &HTTPStatusError{Status: resp.Status, Body: string(resp.BodyBytes())}
+	}
+	return resp, nil
+}
+
+// HTTPWithTimeout makes an HTTP request, asking the host to give up after
+// timeoutMs milliseconds. The host call blocks the plugin and can't be
+// cancelled from inside WASM, so the timeout is best-effort: it only applies
+// if the host honors the request's timeout_ms field.
+func (h Host) HTTPWithTimeout(req HTTPRequest, timeoutMs int) (*HTTPResponse, error) {
+	req.TimeoutMs = timeoutMs
+	return h.HTTP(req)
+}
+
+// HTTPRetry makes an HTTP request up to attempts times, retrying when the
+// request fails with ErrHTTPFailed (unless the host blocked it with
+// ErrHTTPBlocked) or the response status is 5xx, and
+// sleeping backoffMs*n milliseconds after the nth failed attempt. Other
+// errors and non-5xx responses are returned immediately. If every attempt
+// fails, the last error is returned; for a 5xx status this is an
+// *HTTPStatusError along with the response. Responses that are retried are
+// closed.
+//
+// The sleep uses time.Sleep, which TinyGo's WASI target implements with the
+// WASI poll_oneoff call, so it blocks the whole plugin instance. Hosts may
+// also limit how long a call can run, so keep the total backoff short.
+func (h Host) HTTPRetry(req HTTPRequest, attempts int, backoffMs int) (*HTTPResponse, error) {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var resp *HTTPResponse
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		resp, err = h.HTTP(req)
+		switch {
+		case err != nil && (!errors.Is(err, ErrHTTPFailed) || errors.Is(err, ErrHTTPBlocked)):
+			return nil, err
+		case err == nil && resp.Status < 500:
+			return resp, nil
+		case err == nil:
+			err = &HTTPStatusError{Status: resp.Status, Body: string(resp.BodyBytes())}
+		default:
+			resp = nil
+		}
+
+		if attempt == attempts {
+			break
+		}
+		if resp != nil {
+			resp.Close()
+		}
+		if backoffMs > 0 {
+			time.Sleep(time.Duration(backoffMs*attempt) * time.Millisecond)
+		}
+	}
+	return resp, err
+}
+
+// HTTPFollow makes an HTTP request, following up to maxRedirects redirects
+// (301, 302, 303, 307 and 308 responses with a Location header) and
+// recording the URLs it was sent to in the final response's Redirects.
+// Redirect responses that are followed are closed. As
+// with net/http, 303 responses, and 301 and 302 responses to requests other
+// than GET and HEAD, are followed with a bodiless GET, and the
+// Authorization and Cookie headers are dropped when redirected to another
+// host. Exceeding maxRedirects or revisiting a URL returns an error along
+// with the last redirect response.
+//
+// This only has an effect if the host returns redirect responses; hosts that
+// follow redirects themselves never send them.
+func (h Host) HTTPFollow(req HTTPRequest, maxRedirects int) (*HTTPResponse, error) {
+	visited := map[string]bool{req.URL: true}
+	var redirects []string
+
+	for {
+		resp, err := h.HTTP(req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Redirects = redirects
+
+		location := resp.Header("Location")
+		switch resp.Status {
+		case 301, 302, 303, 307, 308:
+		default:
+			return resp, nil
+		}
+		if location == "" {
+			return resp, nil
+		}
+
+		if len(redirects) >= maxRedirects {
+			return resp, fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		next, err := redirectRequest(req, resp.Status, location)
+		if err != nil {
+			return resp, err
+		}
+		if visited[next.URL] {
+			return resp, fmt.Errorf("redirect loop at %s", next.URL)
+		}
+
+		resp.Close()
+		visited[next.URL] = true
+		redirects = append(redirects, next.URL)
+		req = next
+	}
+}
+
+// redirectRequest returns the request to make when req is redirected to
+// location with the given status
+func redirectRequest(req HTTPRequest, status int, location string) (HTTPRequest, error) {
+	base, err := url.Parse(req.URL)
+	if err != nil {
+		return req, fmt.Errorf("invalid request URL %q: %w", req.URL, err)
+	}
+	target, err := base.Parse(location)
+	if err != nil {
+		return req, fmt.Errorf("invalid redirect Location %q: %w", location, err)
+	}
+
+	next := req
+	next.URL = target.String()
+
+	method := strings.ToUpper(req.Method)
+	if status == 303 || ((status == 301 || status == 302) && method != "GET" && method != "HEAD") {
+		next.Method = "GET"
+		next.Body = ""
+		next.BodyBytes = nil
+	}
+
+	if !strings.EqualFold(base.Host, target.Host) {
+		headers := make(map[string]string, len(req.Headers))
+		for key, value := range req.Headers {
+			headers[key] = value
+		}
+		deleteHeader(headers, "Authorization")
+		deleteHeader(headers, "Cookie")
+		next.Headers = headers
+	}
+	return next, nil
+}
+
+// HTTPDecompress makes an HTTP request that accepts compressed responses and
+// transparently decodes gzip and deflate bodies according to the
+// Content-Encoding header. Decoded responses have the Content-Encoding
+// header removed; responses without it are returned unchanged.
+func (h Host) HTTPDecompress(req HTTPRequest) (*HTTPResponse, error) {
+	if !hasHeader(req.Headers, "Accept-Encoding") {
+		headers := make(map[string]string, len(req.Headers)+1)
+		for key, value := range req.Headers {
+			headers[key] = value
+		}
+		headers["Accept-Encoding"] = "gzip, deflate"
+		req.Headers = headers
+	}
+
+	resp, err := h.HTTP(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader
+	body := bytes.NewReader(resp.BodyBytes())
+	switch strings.ToLower(strings.TrimSpace(resp.Header("Content-Encoding"))) {
+	case "":
+		return resp, nil
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip response body: %w", err)
+		}
+		reader = gz
+	case "deflate":
+		// Deflate bodies should be zlib-wrapped, but some servers send raw
+		// deflate data
+		if zr, err := zlib.NewReader(body); err == nil {
+			reader = zr
+		} else {
+			body.Reset(resp.BodyBytes())
+			reader = flate.NewReader(body)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported response Content-Encoding %q", resp.Header("Content-Encoding"))
+	}
+
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress response body: %w", err)
+	}
+
+	resp.body = decoded
+	resp.Body = string(decoded)
+	deleteHeader(resp.Headers, "Content-Encoding")
+	deleteHeader(resp.HeadersMulti, "Content-Encoding")
+	return resp, nil
+}
+
+// normalizeMethod uppercases an HTTP method, defaulting to GET when it is
+// empty, and returns an error if it contains characters not allowed in an
+// HTTP token (RFC 9110)
+func normalizeMethod(method string) (string, error) {
+	if method == "" {
+		return "GET", nil
+	}
+	for i := 0; i < len(method); i++ {
+		if c := method[i]; !isTokenChar(c) {
+			return "", fmt.Errorf("invalid HTTP method %q", method)
+		}
+	}
+	return strings.ToUpper(method), nil
+}
+
+// isTokenChar reports whether c may appear in an HTTP token
+func isTokenChar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	default:
+		return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+	}
+}
+
+// hasHeader reports whether headers contains name, ignoring case
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteHeader removes name from headers, ignoring case
+func deleteHeader[V any](headers map[string]V, name string) {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			delete(headers, key)
+		}
+	}
+}
+
+// Get makes an HTTP GET request
+func (h Host) Get(url string) (*HTTPResponse, error) {
+	return h.HTTP(HTTPRequest{
+		Method: "GET",
+		URL:    url,
+	})
+}
+
+// Post makes an HTTP POST request with the given body and content type. The
+// Content-Type header is omitted if contentType is empty.
+func (h Host) Post(url string, body []byte, contentType string) (*HTTPResponse, error) {
+	req := HTTPRequest{
+		Method:    "POST",
+		URL:       url,
+		BodyBytes: body,
+	}
+	if contentType != "" {
+		req.Headers = map[string]string{"Content-Type": contentType}
+	}
+	return h.HTTP(req)
+}
+
+// PostJSON marshals v to JSON and sends it in an HTTP POST request
+func (h Host) PostJSON(url string, v interface{}) (*HTTPResponse, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return h.Post(url, data, "application/json")
+}
+
+// HTTPJSON makes an HTTP request and unmarshals the JSON response body into
+// out, returning the response status. A non-2xx status returns the status
+// with an *HTTPStatusError and leaves out untouched.
+func (h Host) HTTPJSON(req HTTPRequest, out interface{}) (int, error) {
+	resp, err := h.HTTPExpectOK(req)
+	if resp == nil {
+		return 0, err
+	}
+	if err != nil {
+		return resp.Status, err
+	}
+
+	if err := json.Unmarshal(resp.BodyBytes(), out); err != nil {
+		return resp.Status, fmt.Errorf("invalid JSON response: %w", err)
+	}
+	return resp.Status, nil
+}
+
+// SetBasicAuth sets the request's Authorization header to use HTTP basic
+// authentication with the given username and password
+func (req *HTTPRequest) SetBasicAuth(user, pass string) {
+	credentials := base64.StdEncoding.EncodeToString([]byte(user + ":" + pass))
+	req.setHeader("Authorization", "Basic "+credentials)
+}
+
+// SetBearerToken sets the request's Authorization header to send token as a
+// bearer token
+func (req *HTTPRequest) SetBearerToken(token string) {
+	req.setHeader("Authorization", "Bearer "+token)
+}
+
+// setHeader sets a request header, replacing any existing value regardless
+// of case
+func (req *HTTPRequest) setHeader(name, value string) {
+	if req.Headers == nil {
+		req.Headers = map[string]string{}
+	}
+	deleteHeader(req.Headers, name)
+	req.Headers[name] = value
+}
+
+// statusText holds the standard reason phrases of common HTTP statuses,
+// used when the host doesn't report one. It is kept here rather than using
+// net/http.StatusText to keep net/http out of TinyGo builds.
+var statusText = map[int]string{
+	100: "Continue",
+	101: "Switching Protocols",
+	200: "OK",
+	201: "Created",
+	202: "Accepted",
+	204: "No Content",
+	206: "Partial Content",
+	301: "Moved Permanently",
+	302: "Found",
+	303: "See Other",
+	304: "Not Modified",
+	307: "Temporary Redirect",
+	308: "Permanent Redirect",
+	400: "Bad Request",
+	401: "Unauthorized",
+	403: "Forbidden",
+	404: "Not Found",
+	405: "Method Not Allowed",
+	406: "Not Acceptable",
+	408: "Request Timeout",
+	409: "Conflict",
+	410: "Gone",
+	412: "Precondition Failed",
+	413: "Request Entity Too Large",
+	415: "Unsupported Media Type",
+	422: "Unprocessable Entity",
+	429: "Too Many Requests",
+	500: "Internal Server Error",
+	501: "Not Implemented",
+	502: "Bad Gateway",
+	503: "Service Unavailable",
+	504: "Gateway Timeout",
+}
+
+// ContentType returns the media type of the response's Content-Type header,
+// lowercased and without parameters such as charset, or "" if the header is
+// absent or malformed
+func (r *HTTPResponse) ContentType() string {
+	mediaType, _, err := mime.ParseMediaType(r.Header("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+// Header returns the value of the named response header, ignoring case, or
+// "" if it is absent. Names are compared with strings.EqualFold rather than
+// canonicalized with net/textproto to keep net out of TinyGo builds.
+func (r *HTTPResponse) Header(name string) string {
+	if value, ok := r.Headers[name]; ok {
+		return value
+	}
+	for key, value := range r.Headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+// HeaderValues returns every value of the named response header, ignoring
+// case. It uses HeadersMulti when the host sent it and falls back to Headers.
+func (r *HTTPResponse) HeaderValues(name string) []string {
+	var values []string
+	for key, multi := range r.HeadersMulti {
+		if strings.EqualFold(key, name) {
+			values = append(values, multi...)
+		}
+	}
+	if values != nil {
+		return values
+	}
+
+	if value := r.Header(name); value != "" {
+		return []string{value}
+	}
+	return nil
+}
+
+// Cookies returns the values of every Set-Cookie response header
+func (r *HTTPResponse) Cookies() []string {
+	return r.HeaderValues("Set-Cookie")
+}
+
+// PostForm sends form as an application/x-www-form-urlencoded HTTP POST
+// body. Keys are encoded in sorted order.
+func (h Host) PostForm(rawURL string, form map[string]string) (*HTTPResponse, error) {
+	values := url.Values{}
+	for key, value := range form {
+		values.Set(key, value)
+	}
+	return h.Post(rawURL, []byte(values.Encode()), "application/x-www-form-urlencoded")
+}
+
+// HTTPStreamBody makes an HTTP request whose body is read from body, taking
+// precedence over req.Body and req.BodyBytes. The host receives the whole
+// request at once, so true streaming isn't possible; instead the body is
+// read in chunks and base64-encoded straight into the request JSON in host
+// memory, so it is never held in Go memory. As with OutputStream, the host
+// buffer doubles as it grows and briefly needs up to three times the encoded
+// request size.
+func (h Host) HTTPStreamBody(req HTTPRequest, body io.Reader) (*HTTPResponse, error) {
+	method, err := normalizeMethod(req.Method)
+	if err != nil {
+		return nil, err
+	}
+	req.Method = method
+	req.Body = ""
+	req.BodyBytes = nil
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Splice the body in as the last field of the request object
+	var buf hostBuffer
+	defer buf.free()
+	if err := buf.write(data[:len(data)-1]); err != nil {
+		return nil, err
+	}
+	if err := buf.write([]byte(`,"body_base64":"`)); err != nil {
+		return nil, err
+	}
+	enc := base64.NewEncoder(base64.StdEncoding, hostBufferWriter{buf: &buf})
+	if _, err := io.Copy(enc, body); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	if err := buf.write([]byte(`"}`)); err != nil {
+		return nil, err
+	}
+
+	return h.sendHTTP(Memory{offset: buf.offset, length: buf.length})
+}
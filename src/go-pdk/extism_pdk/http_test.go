@@ -0,0 +1,209 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+// redirect returns a response redirecting to location
+func redirect(location string) *extism_pdk.HTTPResponse {
+	return &extism_pdk.HTTPResponse{Status: 302, Headers: map[string]string{"Location": location}}
+}
+
+// stubRedirects stubs a chain of two redirects ending in a 200 response
+func stubRedirects(m *testhost.MockHost) {
+	m.StubHTTP("https://example.com/a", redirect("/b"))
+	m.StubHTTP("https://example.com/b", redirect("https://example.com/c"))
+	m.StubHTTP("https://example.com/c", &extism_pdk.HTTPResponse{Status: 200, Body: "done"})
+}
+
+func TestHTTPFollow(t *testing.T) {
+	m := &testhost.MockHost{}
+	stubRedirects(m)
+
+	resp, err := m.Host().HTTPFollow(extism_pdk.HTTPRequest{URL: "https://example.com/a"}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != 200 || string(resp.BodyBytes()) != "done" {
+		t.Fatalf("resp = %d %q", resp.Status, resp.BodyBytes())
+	}
+	if len(resp.Redirects) != 2 || resp.Redirects[1] != "https://example.com/c" {
+		t.Fatalf("Redirects = %v", resp.Redirects)
+	}
+}
+
+func TestHTTPFollowLimit(t *testing.T) {
+	m := &testhost.MockHost{}
+	stubRedirects(m)
+
+	resp, err := m.Host().HTTPFollow(extism_pdk.HTTPRequest{URL: "https://example.com/a"}, 1)
+	if err == nil || resp == nil || resp.Status != 302 {
+		t.Fatalf("resp = %v, err = %v, want the last redirect and an error", resp, err)
+	}
+}
+
+func TestHTTPFollowClosesRedirects(t *testing.T) {
+	frees := func(stream bool) int {
+		m := &testhost.MockHost{}
+		stubRedirects(m)
+		req := extism_pdk.HTTPRequest{URL: "https://example.com/a", StreamBody: stream}
+		if _, err := m.Host().HTTPFollow(req, 5); err != nil {
+			t.Fatal(err)
+		}
+		return len(m.Frees)
+	}
+
+	// Each redirect followed frees its body handle
+	if buffered, streamed := frees(false), frees(true); streamed-buffered != 2 {
+		t.Fatalf("frees = %d buffered, %d streamed, want 2 more streamed", buffered, streamed)
+	}
+}
+
+func TestHTTPRetry(t *testing.T) {
+	m := &testhost.MockHost{}
+	m.StubHTTP("https://example.com/", &extism_pdk.HTTPResponse{Status: 503, Body: "busy"})
+
+	resp, err := m.Host().HTTPRetry(extism_pdk.HTTPRequest{URL: "https://example.com/"}, 3, 0)
+	var statusErr *extism_pdk.HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.Status != 503 || statusErr.Body != "busy" {
+		t.Fatalf("err = %v, want a 503 HTTPStatusError", err)
+	}
+	if resp == nil || resp.Status != 503 {
+		t.Fatalf("resp = %v, want the last response", resp)
+	}
+	if len(m.HTTPRequests) != 3 {
+		t.Fatalf("made %d requests, want 3", len(m.HTTPRequests))
+	}
+}
+
+func TestHTTPRetryFailure(t *testing.T) {
+	m := &testhost.MockHost{}
+	_, err := m.Host().HTTPRetry(extism_pdk.HTTPRequest{URL: "https://example.com/"}, 2, 0)
+	if !errors.Is(err, extism_pdk.ErrHTTPFailed) || len(m.HTTPRequests) != 2 {
+		t.Fatalf("err = %v after %d requests", err, len(m.HTTPRequests))
+	}
+}
+
+func TestHTTPRetryClosesRetriedResponses(t *testing.T) {
+	frees := func(stream bool) int {
+		m := &testhost.MockHost{}
+		m.StubHTTP("https://example.com/", &extism_pdk.HTTPResponse{Status: 500})
+		req := extism_pdk.HTTPRequest{URL: "https://example.com/", StreamBody: stream}
+		m.Host().HTTPRetry(req, 3, 0)
+		return len(m.Frees)
+	}
+
+	// Each retried response frees its body handle
+	if buffered, streamed := frees(false), frees(true); streamed-buffered != 2 {
+		t.Fatalf("frees = %d buffered, %d streamed, want 2 more streamed", buffered, streamed)
+	}
+}
+
+func TestHTTPMethodNormalization(t *testing.T) {
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{method: "", want: "GET"},
+		{method: "get", want: "GET"},
+		{method: "Patch", want: "PATCH"},
+	}
+	for _, test := range tests {
+		m := &testhost.MockHost{}
+		m.StubHTTP("https://example.com/", &extism_pdk.HTTPResponse{Status: 200})
+		if _, err := m.Host().HTTP(extism_pdk.HTTPRequest{Method: test.method, URL: "https://example.com/"}); err != nil {
+			t.Fatalf("%q: %v", test.method, err)
+		}
+		if got := m.HTTPRequests[0].Method; got != test.want {
+			t.Errorf("%q sent as %q, want %q", test.method, got, test.want)
+		}
+	}
+}
+
+func TestHTTPInvalidMethod(t *testing.T) {
+	m := &testhost.MockHost{}
+	for _, method := range []string{"GE T", "GET\r\n", "(GET)"} {
+		if _, err := m.Host().HTTP(extism_pdk.HTTPRequest{Method: method, URL: "https://example.com/"}); err == nil {
+			t.Errorf("%q: no error", method)
+		}
+	}
+	if len(m.HTTPRequests) != 0 {
+		t.Fatalf("invalid methods reached the host: %v", m.HTTPRequests)
+	}
+}
+
+func TestHTTPStreamBody(t *testing.T) {
+	m := &testhost.MockHost{}
+	m.StubHTTP("https://example.com/", &extism_pdk.HTTPResponse{Status: 201})
+	body := []byte("binary \x00\xff body")
+
+	req := extism_pdk.HTTPRequest{Method: "post", URL: "https://example.com/"}
+	resp, err := m.Host().HTTPStreamBody(req, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != 201 {
+		t.Fatalf("Status = %d", resp.Status)
+	}
+	if got := m.HTTPRequests[0].BodyBytes; !bytes.Equal(got, body) {
+		t.Fatalf("body = %q, want %q", got, body)
+	}
+	if len(m.Frees) != m.Allocs {
+		t.Fatalf("Allocs = %d, Frees = %v, want every block freed", m.Allocs, m.Frees)
+	}
+}
+
+// benchmarkUpload sends a 1MB body read from an io.Reader, with a fresh host
+// per iteration so simulated host memory doesn't grow across iterations.
+// host-B/op reports the host memory the plugin allocated. The native stubs
+// keep simulated host memory in the Go heap, so B/op counts it too; use
+// -memprofile to see the plugin's own Go allocations, such as the copies of
+// the body the string path makes.
+func benchmarkUpload(b *testing.B, send func(h extism_pdk.Host, req extism_pdk.HTTPRequest, body io.Reader) error) {
+	body := bytes.Repeat([]byte("x"), 1<<20)
+	req := extism_pdk.HTTPRequest{Method: "POST", URL: "https://example.com/"}
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	var hostBytes uint64
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		m := &testhost.MockHost{}
+		m.StubHTTP(req.URL, &extism_pdk.HTTPResponse{Status: 200})
+		h := m.Host()
+		b.StartTimer()
+		if err := send(h, req, bytes.NewReader(body)); err != nil {
+			b.Fatal(err)
+		}
+		hostBytes += m.AllocBytes
+	}
+	b.ReportMetric(float64(hostBytes)/float64(b.N), "host-B/op")
+}
+
+func BenchmarkHTTPStreamBody(b *testing.B) {
+	benchmarkUpload(b, func(h extism_pdk.Host, req extism_pdk.HTTPRequest, body io.Reader) error {
+		_, err := h.HTTPStreamBody(req, body)
+		return err
+	})
+}
+
+// BenchmarkHTTPStringBody reads the body into a string before sending it
+// with HTTP
+func BenchmarkHTTPStringBody(b *testing.B) {
+	benchmarkUpload(b, func(h extism_pdk.Host, req extism_pdk.HTTPRequest, body io.Reader) error {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		req.Body = string(data)
+		_, err = h.HTTP(req)
+		return err
+	})
+}
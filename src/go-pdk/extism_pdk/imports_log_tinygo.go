@@ -0,0 +1,19 @@
+//go:build (tinygo || wasip1) && !extism_wasi_log
+
+package extism_pdk
+
+// The log host functions are declared apart from the other imports so that
+// building with the extism_wasi_log tag leaves them out entirely (see
+// log_wasi.go).
+
+//go:wasmimport env extism_log_info
+func extism_log_info(msg uint64, msg_length uint64)
+
+//go:wasmimport env extism_log_debug
+func extism_log_debug(msg uint64, msg_length uint64)
+
+//go:wasmimport env extism_log_warn
+func extism_log_warn(msg uint64, msg_length uint64)
+
+//go:wasmimport env extism_log_error
+func extism_log_error(msg uint64, msg_length uint64)
@@ -0,0 +1,237 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"unicode/utf8"
+)
+
+// This file implements the Extism host functions natively so the package
+// builds and runs with the standard Go toolchain. Host memory is simulated
+// in a Go slice, and the plugin's input, output, config, vars, logs and HTTP
+// are served by the StubHost installed with UseStubHost. testhost.MockHost
+// installs itself this way, so the real Host methods run under go test.
+
+// StubHost supplies the host state behind the native host function stubs
+type StubHost interface {
+	Input() []byte
+	SetOutput(data []byte)
+	SetError(msg string)
+	Config(key string) (string, bool)
+	Var(key string) ([]byte, bool)
+	SetVar(key string, value []byte)
+	DeleteVar(key string)
+	Log(level LogLevel, msg string)
+	HTTP(req HTTPRequest) (*HTTPResponse, error)
+	// FailAlloc reports whether extism_alloc should fail, returning 0
+	FailAlloc() bool
+	// Allocated and Freed are called for each block the plugin allocates
+	// with extism_alloc or frees with extism_free
+	Allocated(offset uint64, length uint64)
+	Freed(offset uint64)
+}
+
+// stub is the package-level state of the native host
+var stub struct {
+	host       StubHost
+	memory     []byte
+	lengths    map[uint64]uint64
+	httpStatus uint64
+}
+
+// UseStubHost installs the host served by the native stubs and resets the
+// simulated host memory, forgetting the memory pool's blocks along with it.
+// Calling it again with the host already installed leaves memory intact.
+func UseStubHost(host StubHost) {
+	if stub.host == host {
+		return
+	}
+	stub.host = host
+	// Offset 0 is reserved so that it can mean "no block"
+	stub.memory = make([]byte, 1)
+	stub.lengths = map[uint64]uint64{}
+	stub.httpStatus = 0
+	clearMemoryPool()
+}
+
+// IsExtismHost reports whether the Extism host functions are available. In
+// native builds they are only available as stubs once a StubHost, such as
+// testhost.MockHost, is installed, so code under test takes the same path as
+// in a plugin. It only checks a variable, so it is cheap to call.
+func IsExtismHost() bool {
+	return stub.host != nil
+}
+
+// stubHost returns the installed host, panicking if there is none
+func stubHost() StubHost {
+	if stub.host == nil {
+		panic("extism_pdk: no host installed; natively, Host only works with a StubHost such as testhost.MockHost")
+	}
+	return stub.host
+}
+
+// blockSize returns the simulated memory taken by a block of length bytes.
+// Zero-length blocks still take a byte so that every block has a distinct
+// offset.
+func blockSize(length uint64) uint64 {
+	if length == 0 {
+		return 1
+	}
+	return length
+}
+
+// stubAlloc allocates a block of simulated host memory holding data on
+// behalf of the host, so it is unaffected by FailAlloc
+func stubAlloc(data []byte) uint64 {
+	offset := uint64(len(stub.memory))
+	stub.memory = append(stub.memory, make([]byte, blockSize(uint64(len(data))))...)
+	stub.lengths[offset] = uint64(len(data))
+	copy(stub.memory[offset:], data)
+	return offset
+}
+
+// stubLoad returns a block of simulated host memory
+func stubLoad(offset uint64, length uint64) []byte {
+	return stub.memory[offset : offset+length]
+}
+
+// Memory operations
+
+func extism_input_length() uint64 {
+	return uint64(len(stubHost().Input()))
+}
+
+func extism_input_load(offset uint64, length uint64) uint64 {
+	return stubAlloc(stubHost().Input()[offset : offset+length])
+}
+
+func extism_output_set(offset uint64, length uint64) uint64 {
+	stubHost().SetOutput(append([]byte{}, stubLoad(offset, length)...))
+	return 0
+}
+
+func extism_error_set(offset uint64, length uint64) uint64 {
+	stubHost().SetError(string(stubLoad(offset, length)))
+	return 0
+}
+
+func extism_length(id uint64) uint64 {
+	stubHost()
+	return stub.lengths[id]
+}
+
+func extism_alloc(length uint64) uint64 {
+	if stubHost().FailAlloc() {
+		return 0
+	}
+	offset := uint64(len(stub.memory))
+	stub.memory = append(stub.memory, make([]byte, blockSize(length))...)
+	stub.lengths[offset] = length
+	stub.host.Allocated(offset, length)
+	return offset
+}
+
+func extism_free(offset uint64) {
+	stubHost().Freed(offset)
+	delete(stub.lengths, offset)
+}
+
+func extism_store_u8(offset uint64, value uint8) {
+	stub.memory[offset] = value
+}
+
+func extism_store_u64(offset uint64, value uint64) {
+	binary.LittleEndian.PutUint64(stub.memory[offset:offset+8], value)
+}
+
+func extism_load_u8(offset uint64) uint8 {
+	return stub.memory[offset]
+}
+
+func extism_load_u64(offset uint64) uint64 {
+	return binary.LittleEndian.Uint64(stub.memory[offset : offset+8])
+}
+
+// Host functions
+
+func extism_http_request(request uint64, request_length uint64) uint64 {
+	var req HTTPRequest
+	if err := json.Unmarshal(stubLoad(request, request_length), &req); err != nil {
+		return 0
+	}
+
+	resp, err := stubHost().HTTP(req)
+	if err != nil {
+		return 0
+	}
+
+	wire := struct {
+		HTTPResponse
+		BodyBase64 []byte `json:"body_base64,omitempty"`
+		BodyHandle uint64 `json:"body_handle,omitempty"`
+	}{HTTPResponse: *resp}
+
+	if req.StreamBody {
+		// Return the body as a handle to a separate block
+		wire.BodyHandle = stubAlloc(resp.BodyBytes())
+		wire.Body = ""
+	} else if resp.body != nil || !utf8.ValidString(resp.Body) {
+		// Send binary bodies base64-encoded, as JSON strings can't hold them
+		wire.BodyBase64 = resp.BodyBytes()
+	}
+	data, err := json.Marshal(wire)
+	if err != nil {
+		return 0
+	}
+
+	stub.httpStatus = uint64(resp.Status)
+	return stubAlloc(data)
+}
+
+func extism_http_status_code() uint64 {
+	return stub.httpStatus
+}
+
+func extism_config_get(key uint64, key_length uint64) uint64 {
+	value, ok := stubHost().Config(string(stubLoad(key, key_length)))
+	if !ok {
+		return 0
+	}
+	return stubAlloc([]byte(value))
+}
+
+func extism_var_get(key uint64, key_length uint64) uint64 {
+	value, ok := stubHost().Var(string(stubLoad(key, key_length)))
+	if !ok {
+		return 0
+	}
+	return stubAlloc(value)
+}
+
+func extism_var_set(key uint64, key_length uint64, value uint64, value_length uint64) uint64 {
+	name := string(stubLoad(key, key_length))
+	if value_length == 0 {
+		stubHost().DeleteVar(name)
+	} else {
+		stubHost().SetVar(name, append([]byte{}, stubLoad(value, value_length)...))
+	}
+	return 1
+}
+
+func extism_log_info(msg uint64, msg_length uint64) {
+	stubHost().Log(LogLevelInfo, string(stubLoad(msg, msg_length)))
+}
+
+func extism_log_debug(msg uint64, msg_length uint64) {
+	stubHost().Log(LogLevelDebug, string(stubLoad(msg, msg_length)))
+}
+
+func extism_log_warn(msg uint64, msg_length uint64) {
+	stubHost().Log(LogLevelWarn, string(stubLoad(msg, msg_length)))
+}
+
+func extism_log_error(msg uint64, msg_length uint64) {
+	stubHost().Log(LogLevelError, string(stubLoad(msg, msg_length)))
+}
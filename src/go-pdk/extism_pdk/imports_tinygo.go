@@ -0,0 +1,79 @@
+//go:build tinygo || wasip1
+
+package extism_pdk
+
+// This file declares the Extism host functions as WebAssembly imports. It is
+// built by TinyGo and for GOOS=wasip1 with the standard toolchain; native
+// builds use the stubs in imports_native.go. The log functions are declared
+// separately in imports_log_tinygo.go.
+//
+// WebAssembly has no 8-bit integer type, so the u8 functions are imported
+// with uint32 values and wrapped.
+
+// Memory operations - these are imported from the host environment
+//
+//go:wasmimport env extism_input_length
+func extism_input_length() uint64
+
+//go:wasmimport env extism_input_load
+func extism_input_load(offset uint64, length uint64) uint64
+
+//go:wasmimport env extism_output_set
+func extism_output_set(offset uint64, length uint64) uint64
+
+//go:wasmimport env extism_error_set
+func extism_error_set(offset uint64, length uint64) uint64
+
+//go:wasmimport env extism_length
+func extism_length(id uint64) uint64
+
+//go:wasmimport env extism_alloc
+func extism_alloc(length uint64) uint64
+
+//go:wasmimport env extism_free
+func extism_free(offset uint64)
+
+//go:wasmimport env extism_store_u8
+func extism_store_u8_(offset uint64, value uint32)
+
+func extism_store_u8(offset uint64, value uint8) {
+	extism_store_u8_(offset, uint32(value))
+}
+
+//go:wasmimport env extism_store_u64
+func extism_store_u64(offset uint64, value uint64)
+
+//go:wasmimport env extism_load_u8
+func extism_load_u8_(offset uint64) uint32
+
+func extism_load_u8(offset uint64) uint8 {
+	return uint8(extism_load_u8_(offset))
+}
+
+//go:wasmimport env extism_load_u64
+func extism_load_u64(offset uint64) uint64
+
+// Host functions - these are functions provided by the host
+//
+//go:wasmimport env extism_http_request
+func extism_http_request(request uint64, request_length uint64) uint64
+
+//go:wasmimport env extism_http_status_code
+func extism_http_status_code() uint64
+
+//go:wasmimport env extism_config_get
+func extism_config_get(key uint64, key_length uint64) uint64
+
+//go:wasmimport env extism_var_get
+func extism_var_get(key uint64, key_length uint64) uint64
+
+//go:wasmimport env extism_var_set
+func extism_var_set(key uint64, key_length uint64, value uint64, value_length uint64) uint64
+
+// IsExtismHost reports whether the Extism host functions are available. In
+// WebAssembly builds the functions are imports, which the runtime must
+// resolve before the module can start, so this is always true. It is a
+// constant and cheap to call.
+func IsExtismHost() bool {
+	return true
+}
@@ -0,0 +1,82 @@
+package extism_pdk
+
+import "fmt"
+
+// maxInputSize is the largest input GetInputChecked accepts, or 0 for no
+// limit
+var maxInputSize uint64
+
+// SetMaxInputSize sets the largest input in bytes that GetInputChecked
+// accepts. 0, the default, disables the limit.
+func SetMaxInputSize(n uint64) {
+	maxInputSize = n
+}
+
+// InputLength returns the length of the input in bytes without loading it
+func (h Host) InputLength() uint64 {
+	return extism_input_length()
+}
+
+// GetInputChecked returns the input like GetInput, but returns an error
+// without allocating if it is larger than the limit set with
+// SetMaxInputSize, or one wrapping ErrOutOfBounds if the host reports an
+// input length the plugin can't address
+func (h Host) GetInputChecked() ([]byte, error) {
+	length := h.InputLength()
+	if maxInputSize > 0 && length > maxInputSize {
+		return nil, fmt.Errorf("%w: %d bytes exceeds limit of %d bytes", ErrInputTooLarge, length, maxInputSize)
+	}
+	if err := checkRange(0, length); err != nil {
+		return nil, fmt.Errorf("%w: input length %d", err, length)
+	}
+	return h.GetInput(), nil
+}
+
+// GetInputChunked returns the input like GetInput, but loads it from the
+// host at most chunkSize bytes per call, for hosts that limit how much a
+// single call can transfer. A chunkSize of 0 loads it in one call.
+func (h Host) GetInputChunked(chunkSize uint64) []byte {
+	length := extism_input_length()
+	if length == 0 || checkRange(0, length) != nil {
+		return []byte{}
+	}
+	if chunkSize == 0 || chunkSize > length {
+		chunkSize = length
+	}
+
+	data := make([]byte, length)
+	for offset := uint64(0); offset < length; offset += chunkSize {
+		n := chunkSize
+		if remaining := length - offset; n > remaining {
+			n = remaining
+		}
+		readMemoryInto(extism_input_load(offset, n), data[offset:offset+n])
+	}
+	return data
+}
+
+// inputScratch is the buffer reused by InputBytesUnsafe
+var inputScratch []byte
+
+// InputBytesUnsafe returns the input in a buffer that is reused across
+// calls, avoiding an allocation per call for read-only processing such as
+// hashing or scanning.
+//
+// Extism host memory lives outside the plugin's linear memory, so it can't
+// be aliased directly: the input is still copied once, but into memory the
+// PDK keeps. The returned slice is only valid until the next call to
+// InputBytesUnsafe and must not be mutated or retained; copy it (or use
+// GetInput) to keep the data.
+func (h Host) InputBytesUnsafe() []byte {
+	length := extism_input_length()
+	if length == 0 || checkRange(0, length) != nil {
+		return nil
+	}
+	if uint64(cap(inputScratch)) < length {
+		inputScratch = make([]byte, length)
+	}
+
+	buf := inputScratch[:length]
+	readMemoryInto(extism_input_load(0, length), buf)
+	return buf
+}
@@ -0,0 +1,64 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+// benchmarkInput runs fn against a 4MB input, with a fresh host per
+// iteration since the stubs allocate simulated host memory for every input
+// load
+func benchmarkInput(b *testing.B, fn func(h extism_pdk.Host)) {
+	input := make([]byte, 4<<20)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		h := (&testhost.MockHost{Input: input}).Host()
+		b.StartTimer()
+		fn(h)
+	}
+}
+
+func TestInputBytesUnsafe(t *testing.T) {
+	m := &testhost.MockHost{Input: []byte("data")}
+	h := m.Host()
+
+	first := h.InputBytesUnsafe()
+	if string(first) != "data" {
+		t.Fatalf("InputBytesUnsafe = %q", first)
+	}
+	if second := h.InputBytesUnsafe(); &second[0] != &first[0] {
+		t.Fatal("InputBytesUnsafe did not reuse its buffer")
+	}
+}
+
+func BenchmarkSHA256InputBytesUnsafe(b *testing.B) {
+	benchmarkInput(b, func(h extism_pdk.Host) {
+		sha256.Sum256(h.InputBytesUnsafe())
+	})
+}
+
+func BenchmarkSHA256GetInput(b *testing.B) {
+	benchmarkInput(b, func(h extism_pdk.Host) {
+		sha256.Sum256(h.GetInput())
+	})
+}
+
+func TestGetInputChunked(t *testing.T) {
+	m := &testhost.MockHost{Input: []byte("0123456789")}
+	h := m.Host()
+
+	// 3 doesn't divide the input length, 0 loads it in one call and 64 is
+	// larger than the input
+	for _, chunkSize := range []uint64{3, 0, 64} {
+		if got := h.GetInputChunked(chunkSize); string(got) != "0123456789" {
+			t.Errorf("GetInputChunked(%d) = %q", chunkSize, got)
+		}
+	}
+}
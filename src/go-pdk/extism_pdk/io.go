@@ -0,0 +1,138 @@
+package extism_pdk
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// inputReader reads the plugin input from host memory on demand
+type inputReader struct {
+	offset uint64
+	length uint64
+}
+
+// InputReader returns a reader over the plugin input that loads it from the
+// host as it is read, rather than copying it all up front like GetInput
+func (h Host) InputReader() io.Reader {
+	return &inputReader{length: extism_input_length()}
+}
+
+// InputReadSeeker returns a seekable reader over the plugin input, for
+// formats that need random access. The reader also implements io.ReaderAt,
+// so for example the input can be opened with
+// zip.NewReader(r.(io.ReaderAt), int64(host.InputLength())).
+func (h Host) InputReadSeeker() io.ReadSeeker {
+	return &inputReader{length: extism_input_length()}
+}
+
+// Read implements io.Reader
+func (r *inputReader) Read(p []byte) (int, error) {
+	n, err := r.ReadAt(p, int64(r.offset))
+	r.offset += uint64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// ReadAt implements io.ReaderAt
+func (r *inputReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	offset := uint64(off)
+	if offset >= r.length {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	n := uint64(len(p))
+	if remaining := r.length - offset; n > remaining {
+		n = remaining
+	}
+
+	ptr := extism_input_load(offset, n)
+	readMemoryInto(ptr, p[:n])
+
+	if n < uint64(len(p)) {
+		return int(n), io.EOF
+	}
+	return int(n), nil
+}
+
+// Seek implements io.Seeker
+func (r *inputReader) Seek(offset int64, whence int) (int64, error) {
+	var base int64
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		base = int64(r.offset)
+	case io.SeekEnd:
+		base = int64(r.length)
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	position := base + offset
+	if position < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.offset = uint64(position)
+	return position, nil
+}
+
+// memoryReader reads a block of host memory on demand
+type memoryReader struct {
+	mem    Memory
+	offset uint64
+}
+
+// Read implements io.Reader
+func (r *memoryReader) Read(p []byte) (int, error) {
+	if r.offset >= r.mem.length {
+		return 0, io.EOF
+	}
+
+	n := uint64(len(p))
+	if remaining := r.mem.length - r.offset; n > remaining {
+		n = remaining
+	}
+	readMemoryInto(r.mem.offset+r.offset, p[:n])
+	r.offset += n
+	return int(n), nil
+}
+
+// outputWriter accumulates the plugin output and sets it once on Close
+type outputWriter struct {
+	host   Host
+	buf    bytes.Buffer
+	closed bool
+}
+
+// OutputWriter returns a writer for composing the plugin output
+// incrementally. Writes are buffered and the output is set once when the
+// writer is closed; closing it a second time returns an error.
+func (h Host) OutputWriter() io.WriteCloser {
+	return &outputWriter{host: h}
+}
+
+// Write implements io.Writer
+func (w *outputWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+	return w.buf.Write(p)
+}
+
+// Close sets the buffered data as the plugin output
+func (w *outputWriter) Close() error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	w.closed = true
+	return w.host.SetOutput(w.buf.Bytes())
+}
@@ -0,0 +1,62 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+func TestInputReadSeeker(t *testing.T) {
+	m := &testhost.MockHost{Input: []byte("0123456789")}
+	r := m.Host().InputReadSeeker()
+
+	if pos, err := r.Seek(0, io.SeekEnd); pos != 10 || err != nil {
+		t.Fatalf("Seek(0, SeekEnd) = %d, %v, want the input length", pos, err)
+	}
+	if n, err := r.Read(make([]byte, 4)); n != 0 || err != io.EOF {
+		t.Fatalf("Read at end = %d, %v, want EOF", n, err)
+	}
+
+	if pos, err := r.Seek(-4, io.SeekEnd); pos != 6 || err != nil {
+		t.Fatalf("Seek(-4, SeekEnd) = %d, %v", pos, err)
+	}
+	if pos, err := r.Seek(-2, io.SeekCurrent); pos != 4 || err != nil {
+		t.Fatalf("Seek(-2, SeekCurrent) = %d, %v", pos, err)
+	}
+	buf := make([]byte, 3)
+	if n, err := r.Read(buf); n != 3 || err != nil || string(buf) != "456" {
+		t.Fatalf("Read = %d, %v, %q", n, err, buf)
+	}
+	if pos, err := r.Seek(0, io.SeekCurrent); pos != 7 || err != nil {
+		t.Fatalf("Seek(0, SeekCurrent) = %d, %v, want the position after Read", pos, err)
+	}
+
+	if _, err := r.Seek(-8, io.SeekCurrent); err == nil {
+		t.Fatal("Seek to a negative position succeeded")
+	}
+	if pos, _ := r.Seek(0, io.SeekCurrent); pos != 7 {
+		t.Fatalf("failed Seek moved the reader to %d", pos)
+	}
+}
+
+func TestInputReadAt(t *testing.T) {
+	m := &testhost.MockHost{Input: []byte("0123456789")}
+	r := m.Host().InputReadSeeker().(io.ReaderAt)
+
+	buf := make([]byte, 4)
+	if n, err := r.ReadAt(buf, 8); n != 2 || err != io.EOF || string(buf[:n]) != "89" {
+		t.Fatalf("ReadAt past the end = %d, %v, %q, want a short read with EOF", n, err, buf[:n])
+	}
+	if n, err := r.ReadAt(buf, 10); n != 0 || err != io.EOF {
+		t.Fatalf("ReadAt at the end = %d, %v, want EOF", n, err)
+	}
+	if n, err := r.ReadAt(buf, 6); n != 4 || err != nil || string(buf) != "6789" {
+		t.Fatalf("ReadAt ending at the end = %d, %v, %q", n, err, buf)
+	}
+	if _, err := r.ReadAt(buf, -1); err == nil {
+		t.Fatal("ReadAt at a negative offset succeeded")
+	}
+}
@@ -0,0 +1,65 @@
+package extism_pdk
+
+import "encoding/json"
+
+// JSONArrayWriter streams a JSON array to the output one element at a time
+// through an OutputStream, so the array is never held as a Go slice. Create
+// one with NewJSONArrayOutput.
+type JSONArrayWriter struct {
+	out    lazyOutput
+	count  int
+	closed bool
+}
+
+// NewJSONArrayOutput returns a writer that sets the output to a JSON array
+// of the elements passed to Append once it is closed. Closing it without
+// appending anything sets the output to [].
+func (h Host) NewJSONArrayOutput() *JSONArrayWriter {
+	return &JSONArrayWriter{out: lazyOutput{host: h}}
+}
+
+// Append marshals v and adds it to the array. If v can't be marshaled the
+// error is returned and the array is unchanged; if the host can't grow the
+// output, the error is returned again by every later call.
+func (w *JSONArrayWriter) Append(v interface{}) error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	if w.out.err != nil {
+		return w.out.err
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	separator := ","
+	if w.count == 0 {
+		separator = "["
+	}
+	if err := w.out.write(append([]byte(separator), data...)); err != nil {
+		return err
+	}
+	w.count++
+	return nil
+}
+
+// Close terminates the array and sets it as the output. If an earlier write
+// failed, the output is discarded and that error returned instead.
+func (w *JSONArrayWriter) Close() error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	w.closed = true
+
+	closing := "]"
+	if w.count == 0 {
+		closing = "[]"
+	}
+	if err := w.out.write([]byte(closing)); err != nil {
+		w.out.discard()
+		return err
+	}
+	return w.out.commit()
+}
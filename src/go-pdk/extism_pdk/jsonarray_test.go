@@ -0,0 +1,86 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+func TestJSONArrayOutputEmpty(t *testing.T) {
+	m := &testhost.MockHost{}
+	if err := m.Host().NewJSONArrayOutput().Close(); err != nil {
+		t.Fatal(err)
+	}
+	if string(m.Output) != "[]" {
+		t.Fatalf("Output = %q, want []", m.Output)
+	}
+}
+
+func TestJSONArrayOutput(t *testing.T) {
+	m := &testhost.MockHost{}
+	w := m.Host().NewJSONArrayOutput()
+
+	if err := w.Append(map[string]int{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+	// A value that can't be marshaled leaves the array unchanged
+	if err := w.Append(func() {}); err == nil {
+		t.Fatal("Append of a func succeeded")
+	}
+	if err := w.Append("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := string(m.Output), `[{"a":1},"b"]`; got != want {
+		t.Fatalf("Output = %s, want %s", got, want)
+	}
+	if !json.Valid(m.Output) {
+		t.Fatalf("Output is not valid JSON: %s", m.Output)
+	}
+	if err := w.Close(); !errors.Is(err, extism_pdk.ErrWriterClosed) {
+		t.Fatalf("second Close = %v, want ErrWriterClosed", err)
+	}
+	if err := w.Append(1); !errors.Is(err, extism_pdk.ErrWriterClosed) {
+		t.Fatalf("Append after Close = %v, want ErrWriterClosed", err)
+	}
+}
+
+func TestSetOutputSlice(t *testing.T) {
+	m := &testhost.MockHost{}
+	h := m.Host()
+
+	if err := extism_pdk.SetOutputSlice(h, []int{1, 2, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if string(m.Output) != "[1,2,3]" {
+		t.Fatalf("Output = %s", m.Output)
+	}
+
+	if err := extism_pdk.SetOutputSlice[string](h, nil); err != nil {
+		t.Fatal(err)
+	}
+	if string(m.Output) != "[]" {
+		t.Fatalf("nil slice output = %s, want []", m.Output)
+	}
+}
+
+func TestSetOutputSliceMarshalError(t *testing.T) {
+	m := &testhost.MockHost{Output: []byte("unchanged")}
+	if err := extism_pdk.SetOutputSlice(m.Host(), []interface{}{1, func() {}}); err == nil {
+		t.Fatal("SetOutputSlice with a func succeeded")
+	}
+	if string(m.Output) != "unchanged" {
+		t.Fatalf("Output = %q, want it left unset", m.Output)
+	}
+	if len(m.Frees) != m.Allocs {
+		t.Fatalf("Allocs = %d, Frees = %v, want the discarded output freed", m.Allocs, m.Frees)
+	}
+}
@@ -0,0 +1,94 @@
+package extism_pdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+)
+
+// JSON-RPC 2.0 error codes used by DispatchJSONRPC
+const (
+	JSONRPCParseError     = -32700
+	JSONRPCMethodNotFound = -32601
+	JSONRPCInternalError  = -32603
+	JSONRPCHandlerError   = -32000
+)
+
+// jsonRPCRequest is the input read by DispatchJSONRPC
+type jsonRPCRequest struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// JSONRPCError is the error object DispatchJSONRPC writes when a call fails
+type JSONRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// jsonRPCResponse is the output written by DispatchJSONRPC. Result is set,
+// if only to null, exactly when Error is not.
+type jsonRPCResponse struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *JSONRPCError   `json:"error,omitempty"`
+}
+
+// DispatchJSONRPC routes a JSON-RPC style call within a single export. It
+// reads the input as {"method", "params"}, calls the handler registered for
+// the method with the raw params, and writes {"result": ...} or
+// {"error": {"code", "message"}} as output; an "id" in the input is echoed
+// back. A nil result is written as "result": null, and a handler panic is
+// logged with its stack and reported as an internal error. Failures are
+// reported in the output rather than as the plugin error, so 0 is returned
+// unless the output can't be set.
+//
+//	//export rpc
+//	func rpc() int32 {
+//		return extism_pdk.CreateHost().DispatchJSONRPC(map[string]func(json.RawMessage) (interface{}, error){
+//			"add": add,
+//		})
+//	}
+func (h Host) DispatchJSONRPC(handlers map[string]func(params json.RawMessage) (interface{}, error)) int32 {
+	var req jsonRPCRequest
+	var resp jsonRPCResponse
+
+	if err := h.GetInputJSON(&req); err != nil {
+		resp.Error = &JSONRPCError{Code: JSONRPCParseError, Message: "invalid request: " + err.Error()}
+	} else if fn, ok := handlers[req.Method]; !ok {
+		resp.ID = req.ID
+		resp.Error = &JSONRPCError{Code: JSONRPCMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+	} else {
+		resp.ID = req.ID
+		resp.Result, resp.Error = h.callJSONRPC(fn, req.Params)
+	}
+
+	if err := h.SetOutputJSON(resp); err != nil {
+		h.SetError("failed to set output: " + err.Error())
+		return 1
+	}
+	return 0
+}
+
+// callJSONRPC calls a DispatchJSONRPC handler and encodes its result,
+// converting an error or panic into a JSONRPCError
+func (h Host) callJSONRPC(fn func(json.RawMessage) (interface{}, error), params json.RawMessage) (result json.RawMessage, rpcErr *JSONRPCError) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg := fmt.Sprintf("panic: %v", r)
+			h.LogError(msg + "\n" + string(debug.Stack()))
+			result, rpcErr = nil, &JSONRPCError{Code: JSONRPCInternalError, Message: msg}
+		}
+	}()
+
+	value, err := fn(params)
+	if err != nil {
+		return nil, &JSONRPCError{Code: JSONRPCHandlerError, Message: err.Error()}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, &JSONRPCError{Code: JSONRPCInternalError, Message: "failed to encode result: " + err.Error()}
+	}
+	return data, nil
+}
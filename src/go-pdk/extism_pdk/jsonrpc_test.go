@@ -0,0 +1,79 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+var rpcHandlers = map[string]func(json.RawMessage) (interface{}, error){
+	"add": func(params json.RawMessage) (interface{}, error) {
+		var args [2]int
+		if err := json.Unmarshal(params, &args); err != nil {
+			return nil, err
+		}
+		return args[0] + args[1], nil
+	},
+	"fail": func(json.RawMessage) (interface{}, error) {
+		return nil, errors.New("failed")
+	},
+	"nothing": func(json.RawMessage) (interface{}, error) {
+		return nil, nil
+	},
+	"panic": func(json.RawMessage) (interface{}, error) {
+		panic("boom")
+	},
+}
+
+func TestDispatchJSONRPC(t *testing.T) {
+	tests := []struct {
+		input  string
+		output string
+	}{
+		{
+			input:  `{"id":1,"method":"add","params":[1,2]}`,
+			output: `{"id":1,"result":3}`,
+		},
+		{
+			input:  `{"method":"nothing"}`,
+			output: `{"result":null}`,
+		},
+		{
+			input:  `{"id":"a","method":"missing"}`,
+			output: `{"id":"a","error":{"code":-32601,"message":"method \"missing\" not found"}}`,
+		},
+		{
+			input:  `{"id":2,"method":"fail"}`,
+			output: `{"id":2,"error":{"code":-32000,"message":"failed"}}`,
+		},
+		{
+			input:  `{"id":3,"method":"panic"}`,
+			output: `{"id":3,"error":{"code":-32603,"message":"panic: boom"}}`,
+		},
+	}
+	for _, test := range tests {
+		m := &testhost.MockHost{Input: []byte(test.input)}
+		if code := m.Host().DispatchJSONRPC(rpcHandlers); code != 0 {
+			t.Errorf("%s: code = %d", test.input, code)
+		}
+		if string(m.Output) != test.output {
+			t.Errorf("%s: output = %s, want %s", test.input, m.Output, test.output)
+		}
+	}
+}
+
+func TestDispatchJSONRPCParseError(t *testing.T) {
+	m := &testhost.MockHost{Input: []byte(`{`)}
+	m.Host().DispatchJSONRPC(rpcHandlers)
+
+	var resp struct {
+		Error struct{ Code int }
+	}
+	if err := json.Unmarshal(m.Output, &resp); err != nil || resp.Error.Code != -32700 {
+		t.Fatalf("output = %s", m.Output)
+	}
+}
@@ -0,0 +1,25 @@
+package extism_pdk
+
+import "sync"
+
+// initOnce guards the initialization function passed to Once
+var initOnce sync.Once
+
+// Once runs fn the first time it is called and does nothing afterwards. Call
+// it at the top of each entrypoint to do expensive setup, such as compiling
+// regular expressions or building lookup tables, only on the first
+// invocation.
+//
+// An Extism plugin instance keeps its Go globals between calls, so values
+// stored by fn stay available to later invocations. Host memory does not:
+// blocks returned by the host or allocated through the PDK are only valid
+// during the call that produced them, so fn must not keep Memory values.
+// Globals are lost if the host creates a new plugin instance, which then
+// runs fn again.
+//
+// There is a single guard for the whole plugin, so only the first fn passed
+// to Once ever runs; share one init function between entrypoints. If fn
+// panics, it is still considered to have run.
+func Once(fn func()) {
+	initOnce.Do(fn)
+}
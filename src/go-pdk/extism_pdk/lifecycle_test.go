@@ -0,0 +1,40 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+// setupRuns counts the runs of setup. Once has a single guard for the whole
+// test binary, so this is the only test that may use it.
+var setupRuns int
+
+func setup() {
+	setupRuns++
+}
+
+func TestOnceAcrossDispatchCalls(t *testing.T) {
+	for _, name := range []string{"once_a", "once_b"} {
+		extism_pdk.Register(name, func(h extism_pdk.Host) int32 {
+			extism_pdk.Once(setup)
+			return 0
+		})
+	}
+
+	m := &testhost.MockHost{}
+	m.Host()
+	for i := 0; i < 3; i++ {
+		for _, name := range []string{"once_a", "once_b"} {
+			if code := extism_pdk.Dispatch(name); code != 0 {
+				t.Fatalf("Dispatch(%q) = %d", name, code)
+			}
+		}
+	}
+	if setupRuns != 1 {
+		t.Fatalf("setup ran %d times, want 1", setupRuns)
+	}
+}
@@ -0,0 +1,35 @@
+package extism_pdk
+
+import (
+	"bufio"
+	"strings"
+)
+
+// maxLineLength is the longest line InputLines accepts
+const maxLineLength = 1 << 20
+
+// InputLines returns a scanner over the lines of the input, streamed from
+// the host. Line endings (\n or \r\n) are stripped and a final line without
+// one is still returned. Lines longer than 1MB stop the scan with
+// bufio.ErrTooLong, reported by the scanner's Err method.
+func (h Host) InputLines() *bufio.Scanner {
+	s := bufio.NewScanner(h.InputReader())
+	s.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	return s
+}
+
+// InputLinesSlice returns the lines of the input, split the same way as
+// InputLines but without a line length limit. It loads the whole input, so
+// use InputLines for large inputs.
+func (h Host) InputLinesSlice() []string {
+	input := h.GetInputString()
+	if input == "" {
+		return []string{}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(input, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
@@ -0,0 +1,67 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"bufio"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+// scanLines collects the lines InputLines returns
+func scanLines(h extism_pdk.Host) ([]string, error) {
+	s := h.InputLines()
+	lines := []string{}
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	return lines, s.Err()
+}
+
+func TestInputLines(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{input: "a\nb\nc", want: []string{"a", "b", "c"}},
+		{input: "a\nb\nc\n", want: []string{"a", "b", "c"}},
+		{input: "a\r\n\r\nb\r\n", want: []string{"a", "", "b"}},
+		{input: "", want: []string{}},
+	}
+	for _, test := range tests {
+		m := &testhost.MockHost{Input: []byte(test.input)}
+		h := m.Host()
+
+		got, err := scanLines(h)
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("InputLines(%q) = %q, %v, want %q", test.input, got, err, test.want)
+		}
+		if got := h.InputLinesSlice(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("InputLinesSlice(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
+
+func TestInputLinesTooLong(t *testing.T) {
+	long := strings.Repeat("x", 2<<20)
+	m := &testhost.MockHost{Input: []byte("short\n" + long + "\nafter")}
+	h := m.Host()
+
+	lines, err := scanLines(h)
+	if !errors.Is(err, bufio.ErrTooLong) {
+		t.Fatalf("err = %v, want bufio.ErrTooLong", err)
+	}
+	if len(lines) != 1 || lines[0] != "short" {
+		t.Fatalf("lines before the long line = %q", lines)
+	}
+
+	// InputLinesSlice has no length limit
+	if got := h.InputLinesSlice(); len(got) != 3 || got[1] != long {
+		t.Fatalf("InputLinesSlice returned %d lines", len(got))
+	}
+}
@@ -0,0 +1,173 @@
+package extism_pdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// LogLevel is the severity of a log message
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+// String returns the lowercase name of the level
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+}
+
+// logLevel is the minimum level of messages sent to the host
+var logLevel = LogLevelDebug
+
+// SetLogLevel sets the minimum level of messages sent to the host. Messages
+// below it are dropped before any host memory is allocated. The default,
+// LogLevelDebug, sends everything.
+func SetLogLevel(level LogLevel) {
+	logLevel = level
+}
+
+// maxLogSize is the length in bytes above which log messages are truncated
+var maxLogSize = 8 * 1024
+
+// SetMaxLogSize sets the length in bytes above which log messages are
+// truncated before any host memory is allocated for them. The first n bytes
+// are kept, cut back to a UTF-8 character boundary, followed by a
+// "...[truncated N bytes]" suffix counting the bytes dropped. The default is
+// 8KB; n <= 0 disables truncation. Truncation applies to each message
+// separately, so a message from LogFields may no longer be valid JSON.
+func SetMaxLogSize(n int) {
+	maxLogSize = n
+}
+
+// truncateLog shortens msg to the maximum log size
+func truncateLog(msg string) string {
+	if maxLogSize <= 0 || len(msg) <= maxLogSize {
+		return msg
+	}
+	n := maxLogSize
+	for n > 0 && !utf8.RuneStart(msg[n]) {
+		n--
+	}
+	return fmt.Sprintf("%s...[truncated %d bytes]", msg[:n], len(msg)-n)
+}
+
+// log sends a message to the host log function for its level, or to the log
+// buffer when buffering is enabled, unless it is below the minimum level.
+// Messages over the maximum log size are truncated first.
+func (h Host) log(level LogLevel, msg string) {
+	if level < logLevel {
+		return
+	}
+	msg = truncateLog(msg)
+	if logBuffer.enabled {
+		bufferLog(level, msg)
+		return
+	}
+	writeLog(level, msg)
+}
+
+// sprintf formats a message, returning format unchanged when there are no
+// arguments or verbs so that plain messages are not copied. A format with
+// verbs is still formatted, so that "%%" becomes "%".
+func sprintf(format string, args []interface{}) string {
+	if len(args) == 0 && strings.IndexByte(format, '%') < 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// logf formats and logs a message, skipping the formatting when the level is
+// filtered out
+func (h Host) logf(level LogLevel, format string, args []interface{}) {
+	if level < logLevel {
+		return
+	}
+	h.log(level, sprintf(format, args))
+}
+
+// LogInfof logs a formatted informational message
+func (h Host) LogInfof(format string, args ...interface{}) {
+	h.logf(LogLevelInfo, format, args)
+}
+
+// LogDebugf logs a formatted debug message
+func (h Host) LogDebugf(format string, args ...interface{}) {
+	h.logf(LogLevelDebug, format, args)
+}
+
+// LogWarnf logs a formatted warning message
+func (h Host) LogWarnf(format string, args ...interface{}) {
+	h.logf(LogLevelWarn, format, args)
+}
+
+// LogErrorf logs a formatted error message
+func (h Host) LogErrorf(format string, args ...interface{}) {
+	h.logf(LogLevelError, format, args)
+}
+
+// RequestIDKey is the reserved variable or configuration key holding the
+// host's correlation ID for the current invocation
+const RequestIDKey = "__request_id"
+
+// RequestID returns the correlation ID the host set for the current
+// invocation in the RequestIDKey variable or, failing that, configuration
+// key, or "" if there is none
+func (h Host) RequestID() string {
+	if value, ok := h.lookupVar(RequestIDKey); ok {
+		return string(value)
+	}
+	value, _ := h.lookupConfig(RequestIDKey)
+	return value
+}
+
+// LogFields logs a structured message as a single-line JSON object holding
+// the level, the message, the request ID if the host set one, the function
+// being invoked if known, and the given fields:
+//
+//	{"level":"info","msg":"request done","request_id":"abc123","function":"greet","status":200}
+//
+// The "level" and "msg" keys take precedence over fields of the same name,
+// while "request_id" and "function" fields override the values from the
+// host.
+func (h Host) LogFields(level LogLevel, msg string, fields map[string]interface{}) {
+	if level < logLevel {
+		return
+	}
+
+	entry := make(map[string]interface{}, len(fields)+4)
+	if id := h.RequestID(); id != "" {
+		entry["request_id"] = id
+	}
+	if name := h.FunctionName(); name != "" {
+		entry["function"] = name
+	}
+	for key, value := range fields {
+		entry[key] = value
+	}
+	entry["level"] = level.String()
+	entry["msg"] = msg
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		h.log(level, fmt.Sprintf("%s (failed to encode log fields: %v)", msg, err))
+		return
+	}
+	h.log(level, string(data))
+}
@@ -0,0 +1,24 @@
+//go:build !extism_wasi_log
+
+package extism_pdk
+
+// writeLog sends a message to the host log function for its level. Messages
+// that can't be copied into host memory are dropped.
+func writeLog(level LogLevel, msg string) {
+	mem, err := allocateMemory([]byte(msg))
+	if err != nil {
+		return
+	}
+	defer mem.Free()
+
+	switch level {
+	case LogLevelDebug:
+		extism_log_debug(mem.Offset(), mem.Length())
+	case LogLevelInfo:
+		extism_log_info(mem.Offset(), mem.Length())
+	case LogLevelWarn:
+		extism_log_warn(mem.Offset(), mem.Length())
+	default:
+		extism_log_error(mem.Offset(), mem.Length())
+	}
+}
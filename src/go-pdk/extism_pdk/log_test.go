@@ -0,0 +1,128 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+// logFields logs with LogFields and decodes the captured JSON line
+func logFields(t *testing.T, m *testhost.MockHost, fields map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	m.Host().LogFields(extism_pdk.LogLevelWarn, "request done", fields)
+	if len(m.CapturedLogs) != 1 {
+		t.Fatalf("CapturedLogs = %v", m.CapturedLogs)
+	}
+	if m.CapturedLogs[0].Level != extism_pdk.LogLevelWarn {
+		t.Fatalf("level = %v, want warn", m.CapturedLogs[0].Level)
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(m.CapturedLogs[0].Message), &entry); err != nil {
+		t.Fatalf("log line is not JSON: %v", err)
+	}
+	return entry
+}
+
+func TestLogFields(t *testing.T) {
+	m := &testhost.MockHost{}
+	entry := logFields(t, m, map[string]interface{}{"status": 200, "msg": "ignored"})
+
+	want := map[string]interface{}{"level": "warn", "msg": "request done", "status": float64(200)}
+	if !reflect.DeepEqual(entry, want) {
+		t.Fatalf("entry = %v, want %v", entry, want)
+	}
+}
+
+func TestLogFieldsRequestID(t *testing.T) {
+	m := &testhost.MockHost{Vars: map[string]string{extism_pdk.RequestIDKey: "abc123"}}
+	if got := m.Host().RequestID(); got != "abc123" {
+		t.Fatalf("RequestID = %q", got)
+	}
+	if entry := logFields(t, m, nil); entry["request_id"] != "abc123" {
+		t.Fatalf("entry = %v, want request_id", entry)
+	}
+}
+
+func TestLogFieldsRequestIDFromConfig(t *testing.T) {
+	m := &testhost.MockHost{Config: map[string]string{extism_pdk.RequestIDKey: "cfg"}}
+	if entry := logFields(t, m, nil); entry["request_id"] != "cfg" {
+		t.Fatalf("entry = %v, want request_id from config", entry)
+	}
+}
+
+func TestLogFieldsWithoutRequestID(t *testing.T) {
+	m := &testhost.MockHost{}
+	if entry := logFields(t, m, nil); entry["request_id"] != nil {
+		t.Fatalf("entry = %v, want no request_id", entry)
+	}
+}
+
+// logTruncated logs msg with the given maximum log size and returns the
+// captured message
+func logTruncated(t *testing.T, max int, msg string) string {
+	t.Helper()
+	extism_pdk.SetMaxLogSize(max)
+	t.Cleanup(func() { extism_pdk.SetMaxLogSize(8192) })
+	m := &testhost.MockHost{}
+	m.Host().LogInfo(msg)
+	if len(m.CapturedLogs) != 1 {
+		t.Fatalf("CapturedLogs = %v", m.CapturedLogs)
+	}
+	return m.CapturedLogs[0].Message
+}
+
+func TestLogTruncatedAtDefaultSize(t *testing.T) {
+	m := &testhost.MockHost{}
+	m.Host().LogInfo(strings.Repeat("a", 9000))
+	want := strings.Repeat("a", 8192) + "...[truncated 808 bytes]"
+	if got := m.CapturedLogs[0].Message; got != want {
+		t.Fatalf("message has length %d, want %d", len(got), len(want))
+	}
+
+	m.CapturedLogs = nil
+	m.Host().LogInfo(strings.Repeat("a", 8192))
+	if got := m.CapturedLogs[0].Message; len(got) != 8192 {
+		t.Fatalf("message at the limit has length %d, want 8192", len(got))
+	}
+}
+
+func TestLogTruncatedAtCharacterBoundary(t *testing.T) {
+	// The limit falls inside the two-byte é, which is dropped whole
+	if got, want := logTruncated(t, 4, "aaaéb"), "aaa...[truncated 3 bytes]"; got != want {
+		t.Fatalf("message = %q, want %q", got, want)
+	}
+}
+
+func TestLogTruncationDisabled(t *testing.T) {
+	msg := strings.Repeat("a", 10000)
+	for _, max := range []int{0, -1} {
+		if got := logTruncated(t, max, msg); got != msg {
+			t.Fatalf("SetMaxLogSize(%d): message has length %d, want %d", max, len(got), len(msg))
+		}
+	}
+}
+
+func TestFormattedWithoutArgs(t *testing.T) {
+	m := &testhost.MockHost{}
+	h := m.Host()
+
+	h.LogInfof("disk 100%% full")
+	if len(m.CapturedLogs) != 1 || m.CapturedLogs[0].Message != "disk 100% full" {
+		t.Fatalf("CapturedLogs = %v", m.CapturedLogs)
+	}
+	h.SetErrorf("disk 100%% full")
+	if m.Error != "disk 100% full" {
+		t.Fatalf("SetErrorf: Error = %q", m.Error)
+	}
+	h.Failf("quota 90%% used")
+	if m.Error != "quota 90% used" {
+		t.Fatalf("Failf: Error = %q", m.Error)
+	}
+}
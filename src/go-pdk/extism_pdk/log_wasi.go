@@ -0,0 +1,15 @@
+//go:build extism_wasi_log
+
+package extism_pdk
+
+import (
+	"fmt"
+	"os"
+)
+
+// writeLog prints a message to stderr, prefixed with its level. Building
+// with the extism_wasi_log tag replaces the extism_log_* imports with this,
+// so a plugin can be debugged in a plain WASI runtime such as wasmtime.
+func writeLog(level LogLevel, msg string) {
+	fmt.Fprintf(os.Stderr, "[%s] %s\n", level, msg)
+}
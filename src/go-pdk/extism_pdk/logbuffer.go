@@ -0,0 +1,57 @@
+package extism_pdk
+
+import "strings"
+
+// logBuffer holds the messages logged while log buffering is enabled
+var logBuffer struct {
+	enabled bool
+	lines   []string
+	// level is the most severe level among the buffered lines
+	level LogLevel
+}
+
+// EnableLogBuffering makes the Log* functions accumulate messages instead of
+// sending each one to the host. Buffered messages are sent by FlushLogs, or
+// automatically when the output or error is set, when a metric is emitted
+// and when Guard, Dispatch or Handle return, as a single host call: the
+// lines joined with newlines, logged at the most severe level among them.
+//
+// Messages are sent in the order they were logged, and always before the
+// output or error they precede and any later metric. SetLogLevel still
+// applies when a message is logged. Messages still buffered when a plugin
+// traps or returns without flushing are lost.
+func (h Host) EnableLogBuffering() {
+	logBuffer.enabled = true
+}
+
+// DisableLogBuffering flushes any buffered messages and goes back to sending
+// each message to the host as it is logged
+func (h Host) DisableLogBuffering() {
+	flushLogs()
+	logBuffer.enabled = false
+}
+
+// FlushLogs sends the buffered log messages to the host in one call. It does
+// nothing when the buffer is empty.
+func (h Host) FlushLogs() {
+	flushLogs()
+}
+
+// bufferLog adds a message to the log buffer
+func bufferLog(level LogLevel, msg string) {
+	if len(logBuffer.lines) == 0 || level > logBuffer.level {
+		logBuffer.level = level
+	}
+	logBuffer.lines = append(logBuffer.lines, msg)
+}
+
+// flushLogs sends and empties the log buffer
+func flushLogs() {
+	if len(logBuffer.lines) == 0 {
+		return
+	}
+	msg := strings.Join(logBuffer.lines, "\n")
+	level := logBuffer.level
+	logBuffer.lines = logBuffer.lines[:0]
+	writeLog(level, msg)
+}
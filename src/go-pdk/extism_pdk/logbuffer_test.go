@@ -0,0 +1,109 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+// bufferLogs enables log buffering for the duration of a test
+func bufferLogs(t *testing.T, h extism_pdk.Host) {
+	h.EnableLogBuffering()
+	t.Cleanup(h.DisableLogBuffering)
+}
+
+func TestFlushLogsEmitsAllBufferedLines(t *testing.T) {
+	m := &testhost.MockHost{}
+	h := m.Host()
+	bufferLogs(t, h)
+
+	h.LogInfo("one")
+	h.LogWarn("two")
+	h.LogDebug("three")
+	if len(m.CapturedLogs) != 0 {
+		t.Fatalf("logs sent before flush: %v", m.CapturedLogs)
+	}
+
+	h.FlushLogs()
+	want := []testhost.LogEntry{{Level: extism_pdk.LogLevelWarn, Message: "one\ntwo\nthree"}}
+	if len(m.CapturedLogs) != 1 || m.CapturedLogs[0] != want[0] {
+		t.Fatalf("CapturedLogs = %v, want %v", m.CapturedLogs, want)
+	}
+
+	h.FlushLogs()
+	if len(m.CapturedLogs) != 1 {
+		t.Fatalf("empty flush sent a message: %v", m.CapturedLogs)
+	}
+}
+
+func TestBufferedLogsFlushedBeforeOutput(t *testing.T) {
+	m := &testhost.MockHost{}
+	h := m.Host()
+	bufferLogs(t, h)
+
+	h.LogInfo("working")
+	if err := h.SetOutputString("done"); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.CapturedLogs) != 1 || m.CapturedLogs[0].Message != "working" {
+		t.Fatalf("CapturedLogs = %v", m.CapturedLogs)
+	}
+}
+
+func TestBufferedLogsFlushedOnFail(t *testing.T) {
+	m := &testhost.MockHost{}
+	h := m.Host()
+	bufferLogs(t, h)
+
+	h.LogInfo("step 1")
+	if code := h.Fail("x"); code != 1 {
+		t.Fatalf("Fail returned %d", code)
+	}
+	if len(m.CapturedLogs) != 1 || m.CapturedLogs[0].Message != "step 1\nx" {
+		t.Fatalf("CapturedLogs = %v", m.CapturedLogs)
+	}
+	if m.CapturedLogs[0].Level != extism_pdk.LogLevelError {
+		t.Fatalf("level = %v, want error", m.CapturedLogs[0].Level)
+	}
+}
+
+func TestBufferedLogsFlushedOnHandleError(t *testing.T) {
+	m := &testhost.MockHost{Input: []byte(`{}`)}
+	bufferLogs(t, m.Host())
+
+	code := extism_pdk.Handle(m, func(struct{}) (struct{}, error) {
+		m.LogInfo("about to fail")
+		return struct{}{}, errors.New("boom")
+	})
+	if code != 1 || m.Error != "boom" {
+		t.Fatalf("code = %d, Error = %q", code, m.Error)
+	}
+	if len(m.CapturedLogs) != 1 || m.CapturedLogs[0].Message != "about to fail" {
+		t.Fatalf("CapturedLogs = %v", m.CapturedLogs)
+	}
+}
+
+func TestBufferedLogsFlushedOnHandlePanic(t *testing.T) {
+	m := &testhost.MockHost{Input: []byte(`{}`)}
+	bufferLogs(t, m.Host())
+
+	code := extism_pdk.Handle(m, func(struct{}) (struct{}, error) {
+		m.LogInfo("about to panic")
+		panic("boom")
+	})
+	if code != 1 {
+		t.Fatalf("code = %d", code)
+	}
+	if len(m.CapturedLogs) != 1 {
+		t.Fatalf("CapturedLogs = %v", m.CapturedLogs)
+	}
+	msg := m.CapturedLogs[0].Message
+	if !strings.HasPrefix(msg, "about to panic\npanic: boom\n") || !strings.Contains(msg, "goroutine") {
+		t.Fatalf("log message missing panic or stack: %q", msg)
+	}
+}
@@ -0,0 +1,155 @@
+package extism_pdk
+
+import "unsafe"
+
+// maxSliceLength is the largest length a Go slice can have on this platform
+const maxSliceLength = uint64(^uint(0) >> 1)
+
+// checkRange validates a range of host memory reported by the host,
+// returning ErrOutOfBounds if offset+length overflows or the range is too
+// long to copy into a Go slice
+func checkRange(offset uint64, length uint64) error {
+	if length > maxSliceLength || offset > ^uint64(0)-length {
+		return ErrOutOfBounds
+	}
+	return nil
+}
+
+// readMemory copies length bytes of host memory starting at offset into a
+// new Go slice. An invalid range reads as empty rather than touching
+// arbitrary memory.
+func readMemory(offset uint64, length uint64) []byte {
+	if checkRange(offset, length) != nil {
+		return []byte{}
+	}
+	data := make([]byte, length)
+	readMemoryInto(offset, data)
+	return data
+}
+
+// readMemoryInto fills dst with host memory starting at offset. Data is
+// transferred 8 bytes per host call, with the trailing bytes loaded one at a
+// time, through the bounds-checked Memory loads so that a wrong index panics
+// instead of reading past the range. An invalid range leaves dst untouched.
+func readMemoryInto(offset uint64, dst []byte) {
+	length := uint64(len(dst))
+	if checkRange(offset, length) != nil {
+		return
+	}
+	mem := Memory{offset: offset, length: length}
+	words := length / 8
+	for i := uint64(0); i < words; i++ {
+		*(*uint64)(unsafe.Pointer(&dst[i*8])) = mem.LoadU64(i * 8)
+	}
+	for i := words * 8; i < length; i++ {
+		dst[i] = mem.LoadU8(i)
+	}
+}
+
+// writeMemory copies data into host memory starting at offset. Data is
+// transferred 8 bytes per host call, with the trailing bytes stored one at a
+// time, through the bounds-checked Memory stores.
+func writeMemory(offset uint64, data []byte) {
+	length := uint64(len(data))
+	mem := Memory{offset: offset, length: length}
+	words := length / 8
+	for i := uint64(0); i < words; i++ {
+		mem.StoreU64(i*8, *(*uint64)(unsafe.Pointer(&data[i*8])))
+	}
+	for i := words * 8; i < length; i++ {
+		mem.StoreU8(i, data[i])
+	}
+}
+
+// copyMemory copies length bytes of host memory from src to dst without
+// staging them in Go memory. The blocks must not overlap.
+func copyMemory(dst uint64, src uint64, length uint64) {
+	words := length / 8
+	for i := uint64(0); i < words; i++ {
+		extism_store_u64(dst+i*8, extism_load_u64(src+i*8))
+	}
+	for i := words * 8; i < length; i++ {
+		extism_store_u8(dst+i, extism_load_u8(src+i))
+	}
+}
+
+// Memory is a block of host memory identified by its offset and length
+type Memory struct {
+	offset uint64
+	length uint64
+}
+
+// AllocateMemory allocates a block of host memory and copies data into it.
+// If the host cannot allocate the block, the returned Memory has offset 0.
+func AllocateMemory(data []byte) Memory {
+	mem, _ := allocateMemory(data)
+	return mem
+}
+
+// allocateMemory allocates a block of host memory and copies data into it,
+// returning an error instead of writing to offset 0 if allocation fails
+func allocateMemory(data []byte) (Memory, error) {
+	length := uint64(len(data))
+	offset := allocate(length)
+	if offset == 0 && length > 0 {
+		return Memory{}, ErrAllocFailed
+	}
+	writeMemory(offset, data)
+	return Memory{offset: offset, length: length}, nil
+}
+
+// NewMemory wraps an existing block of host memory, such as one returned by
+// a custom host function. The host may reuse the offset of a block freed
+// earlier, so it is no longer considered freed.
+func NewMemory(offset uint64, length uint64) Memory {
+	delete(freedBlocks, offset)
+	return Memory{offset: offset, length: length}
+}
+
+// findMemory wraps a block of host memory returned by the host, looking up
+// its length. A block whose length would run past the end of the address
+// space is treated as empty. The host may reuse the offset of a block freed
+// earlier, so it is no longer considered freed.
+func findMemory(offset uint64) Memory {
+	delete(freedBlocks, offset)
+	length := extism_length(offset)
+	if checkRange(offset, length) != nil {
+		length = 0
+	}
+	return Memory{offset: offset, length: length}
+}
+
+// Load copies the contents of the block into a new Go slice
+func (m Memory) Load() []byte {
+	return readMemory(m.offset, m.length)
+}
+
+// LoadString copies the contents of the block into a string
+func (m Memory) LoadString() string {
+	return string(m.Load())
+}
+
+// Free releases the block back to the host. Freeing a block with offset 0,
+// or one already freed during the current Guard, Dispatch or Handle call,
+// does nothing. Outside those calls the PDK can't tell invocations apart, so
+// a block freed twice is passed to the host twice.
+func (m Memory) Free() {
+	release(m.offset)
+}
+
+// Free releases the block of host memory at offset, like Memory.Free.
+// Freeing offset 0 or a block already freed during the current Guard,
+// Dispatch or Handle call does nothing.
+func (h Host) Free(offset uint64) {
+	release(offset)
+}
+
+// Offset returns the host offset of the block
+func (m Memory) Offset() uint64 {
+	return m.offset
+}
+
+// Length returns the length of the block in bytes
+func (m Memory) Length() uint64 {
+	return m.length
+}
@@ -0,0 +1,112 @@
+package extism_pdk
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// loadU16 loads a little-endian uint16 from host memory
+func loadU16(offset uint64) uint16 {
+	return uint16(extism_load_u8(offset)) | uint16(extism_load_u8(offset+1))<<8
+}
+
+// loadU32 loads a little-endian uint32 from host memory
+func loadU32(offset uint64) uint32 {
+	return uint32(loadU16(offset)) | uint32(loadU16(offset+2))<<16
+}
+
+// storeU16 stores a little-endian uint16 in host memory
+func storeU16(offset uint64, value uint16) {
+	extism_store_u8(offset, uint8(value))
+	extism_store_u8(offset+1, uint8(value>>8))
+}
+
+// storeU32 stores a little-endian uint32 in host memory
+func storeU32(offset uint64, value uint32) {
+	storeU16(offset, uint16(value))
+	storeU16(offset+2, uint16(value>>16))
+}
+
+// check panics if size bytes at offset don't fit within the block, like an
+// out-of-range slice index
+func (m Memory) check(offset uint64, size uint64) {
+	if offset > m.length || m.length-offset < size {
+		panic(fmt.Sprintf("extism_pdk: %d-byte access at offset %d out of range for memory of length %d", size, offset, m.length))
+	}
+}
+
+// LoadU8 loads the byte at index i within the block. It panics if i is not
+// less than the block's length, rather than reading past the block.
+func (m Memory) LoadU8(i uint64) uint8 {
+	m.check(i, 1)
+	return extism_load_u8(m.offset + i)
+}
+
+// LoadU64 loads the little-endian uint64 at index i within the block. It
+// panics if the 8 bytes don't fit in the block.
+func (m Memory) LoadU64(i uint64) uint64 {
+	m.check(i, 8)
+	return extism_load_u64(m.offset + i)
+}
+
+// StoreU8 stores a byte at index i within the block. It panics if i is not
+// less than the block's length, rather than corrupting memory past the block.
+func (m Memory) StoreU8(i uint64, value uint8) {
+	m.check(i, 1)
+	extism_store_u8(m.offset+i, value)
+}
+
+// StoreU64 stores a little-endian uint64 at index i within the block. It
+// panics if the 8 bytes don't fit in the block.
+func (m Memory) StoreU64(i uint64, value uint64) {
+	m.check(i, 8)
+	extism_store_u64(m.offset+i, value)
+}
+
+// ReadUint16LE reads a little-endian uint16 at offset within the block. It
+// panics if the value doesn't fit in the block.
+func (m Memory) ReadUint16LE(offset uint64) uint16 {
+	m.check(offset, 2)
+	return loadU16(m.offset + offset)
+}
+
+// ReadUint16BE reads a big-endian uint16 at offset within the block
+func (m Memory) ReadUint16BE(offset uint64) uint16 {
+	return bits.ReverseBytes16(m.ReadUint16LE(offset))
+}
+
+// ReadUint32LE reads a little-endian uint32 at offset within the block. It
+// panics if the value doesn't fit in the block.
+func (m Memory) ReadUint32LE(offset uint64) uint32 {
+	m.check(offset, 4)
+	return loadU32(m.offset + offset)
+}
+
+// ReadUint32BE reads a big-endian uint32 at offset within the block
+func (m Memory) ReadUint32BE(offset uint64) uint32 {
+	return bits.ReverseBytes32(m.ReadUint32LE(offset))
+}
+
+// WriteUint16LE writes a little-endian uint16 at offset within the block. It
+// panics if the value doesn't fit in the block.
+func (m Memory) WriteUint16LE(offset uint64, value uint16) {
+	m.check(offset, 2)
+	storeU16(m.offset+offset, value)
+}
+
+// WriteUint16BE writes a big-endian uint16 at offset within the block
+func (m Memory) WriteUint16BE(offset uint64, value uint16) {
+	m.WriteUint16LE(offset, bits.ReverseBytes16(value))
+}
+
+// WriteUint32LE writes a little-endian uint32 at offset within the block. It
+// panics if the value doesn't fit in the block.
+func (m Memory) WriteUint32LE(offset uint64, value uint32) {
+	m.check(offset, 4)
+	storeU32(m.offset+offset, value)
+}
+
+// WriteUint32BE writes a big-endian uint32 at offset within the block
+func (m Memory) WriteUint32BE(offset uint64, value uint32) {
+	m.WriteUint32LE(offset, bits.ReverseBytes32(value))
+}
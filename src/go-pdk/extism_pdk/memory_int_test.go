@@ -0,0 +1,94 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+func TestReadUint(t *testing.T) {
+	m := &testhost.MockHost{}
+	m.Host()
+	mem := extism_pdk.AllocateMemory([]byte{0x01, 0x02, 0x03, 0x04, 0x05})
+
+	if got := mem.ReadUint16LE(1); got != 0x0302 {
+		t.Errorf("ReadUint16LE = %#x", got)
+	}
+	if got := mem.ReadUint16BE(1); got != 0x0203 {
+		t.Errorf("ReadUint16BE = %#x", got)
+	}
+	if got := mem.ReadUint32LE(1); got != 0x05040302 {
+		t.Errorf("ReadUint32LE = %#x", got)
+	}
+	if got := mem.ReadUint32BE(1); got != 0x02030405 {
+		t.Errorf("ReadUint32BE = %#x", got)
+	}
+}
+
+func TestWriteUint(t *testing.T) {
+	m := &testhost.MockHost{}
+	m.Host()
+	mem := extism_pdk.AllocateMemory(make([]byte, 12))
+
+	mem.WriteUint16LE(0, 0x0102)
+	mem.WriteUint16BE(2, 0x0102)
+	mem.WriteUint32LE(4, 0x01020304)
+	mem.WriteUint32BE(8, 0x01020304)
+
+	want := []byte{0x02, 0x01, 0x01, 0x02, 0x04, 0x03, 0x02, 0x01, 0x01, 0x02, 0x03, 0x04}
+	if got := mem.Load(); !bytes.Equal(got, want) {
+		t.Fatalf("memory = % x, want % x", got, want)
+	}
+}
+
+func TestReadUintOutOfRange(t *testing.T) {
+	m := &testhost.MockHost{}
+	m.Host()
+	mem := extism_pdk.AllocateMemory([]byte{0x01, 0x02, 0x03})
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("ReadUint32LE past the end of the block did not panic")
+		}
+	}()
+	mem.ReadUint32LE(0)
+}
+
+// mustPanic reports whether fn panics
+func mustPanic(fn func()) (panicked bool) {
+	defer func() { panicked = recover() != nil }()
+	fn()
+	return false
+}
+
+func TestLoadStoreBounds(t *testing.T) {
+	m := &testhost.MockHost{}
+	m.Host()
+	mem := extism_pdk.AllocateMemory(make([]byte, 9))
+
+	mem.StoreU8(8, 0xff)
+	if got := mem.LoadU8(8); got != 0xff {
+		t.Errorf("LoadU8(8) = %#x", got)
+	}
+	mem.StoreU64(1, 0x0102030405060708)
+	if got := mem.LoadU64(1); got != 0x0102030405060708 {
+		t.Errorf("LoadU64(1) = %#x", got)
+	}
+
+	outOfRange := map[string]func(){
+		"LoadU8(9)":   func() { mem.LoadU8(9) },
+		"StoreU8(9)":  func() { mem.StoreU8(9, 0) },
+		"LoadU64(2)":  func() { mem.LoadU64(2) },
+		"StoreU64(2)": func() { mem.StoreU64(2, 0) },
+		"LoadU64(^0)": func() { mem.LoadU64(^uint64(0)) },
+	}
+	for name, fn := range outOfRange {
+		if !mustPanic(fn) {
+			t.Errorf("%s did not panic", name)
+		}
+	}
+}
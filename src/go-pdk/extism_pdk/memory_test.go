@@ -0,0 +1,176 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+func TestFreeTwiceFreesOnce(t *testing.T) {
+	m := &testhost.MockHost{}
+	h := m.Host()
+
+	extism_pdk.Guard(func() int32 {
+		mem := extism_pdk.AllocateMemory([]byte("data"))
+		mem.Free()
+		mem.Free()
+		h.Free(mem.Offset())
+		h.Free(0)
+		return 0
+	})
+	if len(m.Frees) != 1 {
+		t.Fatalf("Frees = %v, want one", m.Frees)
+	}
+}
+
+func TestFreeTwiceAroundNestedGuard(t *testing.T) {
+	m := &testhost.MockHost{}
+	m.Host()
+
+	extism_pdk.Guard(func() int32 {
+		mem := extism_pdk.AllocateMemory([]byte("data"))
+		mem.Free()
+		extism_pdk.Guard(func() int32 { return 0 })
+		mem.Free()
+		return 0
+	})
+	if len(m.Frees) != 1 {
+		t.Fatalf("Frees = %v, want one", m.Frees)
+	}
+}
+
+func TestFreeReusedOffset(t *testing.T) {
+	m := &testhost.MockHost{}
+	m.Host()
+
+	extism_pdk.Guard(func() int32 {
+		mem := extism_pdk.AllocateMemory([]byte("data"))
+		mem.Free()
+		// A custom host function may hand the freed offset out again
+		extism_pdk.NewMemory(mem.Offset(), mem.Length()).Free()
+		return 0
+	})
+	if len(m.Frees) != 2 {
+		t.Fatalf("Frees = %v, want the reused block freed too", m.Frees)
+	}
+}
+
+func TestFreedBlocksForgottenBetweenCalls(t *testing.T) {
+	m := &testhost.MockHost{}
+	m.Host()
+	mem := extism_pdk.AllocateMemory([]byte("data"))
+
+	for i := 0; i < 2; i++ {
+		extism_pdk.Guard(func() int32 {
+			mem.Free()
+			return 0
+		})
+	}
+	if len(m.Frees) != 2 {
+		t.Fatalf("Frees = %v, want one per call", m.Frees)
+	}
+}
+
+func TestLoadOverflowingRange(t *testing.T) {
+	m := &testhost.MockHost{}
+	m.Host()
+	mem := extism_pdk.AllocateMemory([]byte("data"))
+
+	ranges := []struct{ offset, length uint64 }{
+		{mem.Offset(), ^uint64(0)},
+		{^uint64(0) - 1, 4},
+		{^uint64(0), 1},
+	}
+	for _, r := range ranges {
+		// An invalid range reads as empty instead of touching host memory
+		if got := extism_pdk.NewMemory(r.offset, r.length).Load(); len(got) != 0 {
+			t.Errorf("Load(%d, %d) = %q, want empty", r.offset, r.length, got)
+		}
+	}
+}
+
+func TestGetInputCheckedLimit(t *testing.T) {
+	m := &testhost.MockHost{Input: []byte("0123456789")}
+	h := m.Host()
+	extism_pdk.SetMaxInputSize(4)
+	defer extism_pdk.SetMaxInputSize(0)
+
+	if _, err := h.GetInputChecked(); !errors.Is(err, extism_pdk.ErrInputTooLarge) {
+		t.Fatalf("err = %v, want ErrInputTooLarge", err)
+	}
+	if m.Allocs != 0 {
+		t.Fatalf("Allocs = %d, want the input rejected before loading", m.Allocs)
+	}
+
+	extism_pdk.SetMaxInputSize(10)
+	if data, err := h.GetInputChecked(); err != nil || string(data) != "0123456789" {
+		t.Fatalf("GetInputChecked = %q, %v", data, err)
+	}
+}
+
+// copySizes are the payload sizes the memory copy benchmarks run at
+var copySizes = []struct {
+	name string
+	size int
+}{
+	{"1KB", 1 << 10},
+	{"64KB", 64 << 10},
+	{"1MB", 1 << 20},
+}
+
+// benchmarkCopy runs fn for each payload size with a fresh host per
+// iteration, so simulated host memory doesn't grow across iterations. Each
+// fn copies the payload in with AllocateMemory, so the bytewise variants
+// measure the extra cost of copying one byte per host call.
+func benchmarkCopy(b *testing.B, fn func(data []byte)) {
+	for _, s := range copySizes {
+		b.Run(s.name, func(b *testing.B) {
+			data := make([]byte, s.size)
+			b.SetBytes(int64(s.size))
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				(&testhost.MockHost{}).Host()
+				b.StartTimer()
+				fn(data)
+			}
+		})
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	benchmarkCopy(b, func(data []byte) {
+		extism_pdk.AllocateMemory(data).Load()
+	})
+}
+
+// BenchmarkLoadBytewise loads a block one byte per host call, as the copies
+// did before they moved to 8-byte words
+func BenchmarkLoadBytewise(b *testing.B) {
+	benchmarkCopy(b, func(data []byte) {
+		mem := extism_pdk.AllocateMemory(data)
+		buf := make([]byte, mem.Length())
+		for i := range buf {
+			buf[i] = mem.LoadU8(uint64(i))
+		}
+	})
+}
+
+func BenchmarkStore(b *testing.B) {
+	benchmarkCopy(b, func(data []byte) {
+		extism_pdk.AllocateMemory(data)
+	})
+}
+
+// BenchmarkStoreBytewise stores a block one byte per host call
+func BenchmarkStoreBytewise(b *testing.B) {
+	benchmarkCopy(b, func(data []byte) {
+		mem := extism_pdk.AllocateMemory(data)
+		for i, c := range data {
+			mem.StoreU8(uint64(i), c)
+		}
+	})
+}
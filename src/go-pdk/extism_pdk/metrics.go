@@ -0,0 +1,43 @@
+package extism_pdk
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// metric is the JSON log line written by EmitMetric
+type metric struct {
+	Type      string            `json:"type"`
+	Name      string            `json:"name"`
+	Value     float64           `json:"value"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+// EmitMetric reports a metric to the host as a single-line JSON object
+// logged at info level:
+//
+//	{"type":"metric","name":"cache.hits","value":3,"tags":{"region":"eu"},"request_id":"abc123"}
+//
+// Hosts can collect metrics by parsing info log lines whose "type" is
+// "metric". Tags and request_id are omitted when empty. Metrics are sent
+// regardless of SetLogLevel; NaN and infinite values can't be encoded as
+// JSON and are dropped.
+func (h Host) EmitMetric(name string, value float64, tags map[string]string) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return
+	}
+
+	data, err := json.Marshal(metric{
+		Type:      "metric",
+		Name:      name,
+		Value:     value,
+		Tags:      tags,
+		RequestID: h.RequestID(),
+	})
+	if err != nil {
+		return
+	}
+	flushLogs()
+	writeLog(LogLevelInfo, string(data))
+}
@@ -0,0 +1,43 @@
+// Package msgpack provides MessagePack input and output helpers for Extism
+// plugins. It lives outside the core extism_pdk package so that only plugins
+// which import it depend on github.com/vmihailenco/msgpack/v5.
+//
+// Importing the package registers its decoder and encoder with
+// extism_pdk.RegisterInputDecoder and extism_pdk.RegisterOutputEncoder, so
+// Host.DecodeInput accepts MessagePack input and Host.RespondNegotiated can
+// produce MessagePack output.
+package msgpack
+
+import (
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func init() {
+	decode := func(h extism_pdk.Host, v interface{}) error {
+		return GetInputMsgPack(h, v)
+	}
+	encode := func(h extism_pdk.Host, v interface{}) error {
+		return SetOutputMsgPack(h, v)
+	}
+	for _, mediaType := range []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"} {
+		extism_pdk.RegisterInputDecoder(mediaType, decode)
+		extism_pdk.RegisterOutputEncoder(mediaType, encode)
+	}
+}
+
+// GetInputMsgPack unmarshals the MessagePack input into the provided
+// interface. It mirrors HostAPI.GetInputJSON.
+func GetInputMsgPack(host extism_pdk.HostAPI, v interface{}) error {
+	return msgpack.Unmarshal(host.GetInput(), v)
+}
+
+// SetOutputMsgPack marshals the provided interface to MessagePack and sets it
+// as output. It mirrors HostAPI.SetOutputJSON.
+func SetOutputMsgPack(host extism_pdk.HostAPI, v interface{}) error {
+	data, err := msgpack.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return host.SetOutput(data)
+}
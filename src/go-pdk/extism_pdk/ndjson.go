@@ -0,0 +1,51 @@
+package extism_pdk
+
+import "encoding/json"
+
+// NDJSONWriter streams newline-delimited JSON to the output, one record per
+// line, through an OutputStream. Create one with NewNDJSONWriter.
+type NDJSONWriter struct {
+	out    lazyOutput
+	closed bool
+}
+
+// NewNDJSONWriter returns a writer that sets the output to the records
+// passed to Write, each marshaled as JSON and followed by a newline, once it
+// is closed. Closing it without writing anything sets an empty output.
+func (h Host) NewNDJSONWriter() *NDJSONWriter {
+	return &NDJSONWriter{out: lazyOutput{host: h}}
+}
+
+// Write marshals v and adds it as a line of output. json.Marshal never
+// produces newlines, so each record stays on its own line. If v can't be
+// marshaled the error is returned and nothing is written; if the host can't
+// grow the output, the error is returned again by every later call.
+func (w *NDJSONWriter) Write(v interface{}) error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	if w.out.err != nil {
+		return w.out.err
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return w.out.write(append(data, '\n'))
+}
+
+// Close sets the written records as the output. If an earlier write failed,
+// the output is discarded and that error returned instead.
+func (w *NDJSONWriter) Close() error {
+	if w.closed {
+		return ErrWriterClosed
+	}
+	w.closed = true
+
+	if w.out.err != nil {
+		w.out.discard()
+		return w.out.err
+	}
+	return w.out.commit()
+}
@@ -0,0 +1,62 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+func TestNDJSONWriter(t *testing.T) {
+	m := &testhost.MockHost{}
+	w := m.Host().NewNDJSONWriter()
+
+	records := []interface{}{
+		map[string]string{"text": "line\nbreak"},
+		[]int{1, 2},
+		"plain",
+	}
+	for _, record := range records {
+		if err := w.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "{\"text\":\"line\\nbreak\"}\n[1,2]\n\"plain\"\n"
+	if string(m.Output) != want {
+		t.Fatalf("Output = %q, want %q", m.Output, want)
+	}
+}
+
+func TestNDJSONWriterEmpty(t *testing.T) {
+	m := &testhost.MockHost{}
+	if err := m.Host().NewNDJSONWriter().Close(); err != nil {
+		t.Fatal(err)
+	}
+	if m.Output == nil || len(m.Output) != 0 {
+		t.Fatalf("Output = %#v, want set but empty", m.Output)
+	}
+}
+
+func TestNDJSONWriterClosed(t *testing.T) {
+	m := &testhost.MockHost{}
+	w := m.Host().NewNDJSONWriter()
+	w.Write(1)
+	w.Close()
+
+	if err := w.Write(2); !errors.Is(err, extism_pdk.ErrWriterClosed) {
+		t.Fatalf("Write after Close = %v, want ErrWriterClosed", err)
+	}
+	if err := w.Close(); !errors.Is(err, extism_pdk.ErrWriterClosed) {
+		t.Fatalf("second Close = %v, want ErrWriterClosed", err)
+	}
+	if string(m.Output) != "1\n" {
+		t.Fatalf("Output = %q", m.Output)
+	}
+}
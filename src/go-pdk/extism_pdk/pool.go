@@ -0,0 +1,151 @@
+package extism_pdk
+
+// Blocks of up to maxPooledSize bytes are pooled in power-of-two size
+// classes starting at minSizeClass
+const (
+	minSizeClass  = 16
+	maxPooledSize = 4096
+)
+
+// memoryPool retains freed small blocks so that later allocations of the
+// same size class can reuse them without calling the host allocator
+var memoryPool struct {
+	enabled bool
+	// free holds the offsets of released blocks by size class
+	free map[uint64][]uint64
+	// classes records the size class of every block the pool allocated
+	classes map[uint64]uint64
+}
+
+// calls is the number of Guard calls in progress. Host memory only stays
+// valid within a call, so the pool only hands out blocks while one is.
+var calls int
+
+// freedBlocks holds the offsets of blocks freed during the current Guard
+// call, so that freeing one again does nothing. An offset is removed when
+// the host hands it out again. Outside a call invocations can't be told
+// apart, so nothing is recorded.
+var freedBlocks map[uint64]bool
+
+// EnableMemoryPool turns pooling of small host memory blocks on or off.
+// While enabled, freed blocks of up to 4KB are kept and handed back to later
+// allocations of the same size class. Host memory does not survive between
+// calls, so pooling only takes effect inside Guard, Dispatch and Handle,
+// and the pool is emptied when the outermost of them returns; elsewhere
+// blocks go straight to the host allocator.
+func EnableMemoryPool(enabled bool) {
+	if !enabled {
+		ResetMemoryPool()
+	}
+	memoryPool.enabled = enabled
+}
+
+// ResetMemoryPool frees every block held by the memory pool
+func ResetMemoryPool() {
+	for _, offsets := range memoryPool.free {
+		for _, offset := range offsets {
+			extism_free(offset)
+		}
+	}
+	memoryPool.free = nil
+	memoryPool.classes = nil
+}
+
+// beginCall marks the start of a Guard call, forgetting blocks freed
+// before it
+func beginCall() {
+	if calls == 0 {
+		freedBlocks = nil
+	}
+	calls++
+}
+
+// endCall marks the end of a Guard call. Once the outermost call ends the
+// memory pool is emptied and the blocks freed during it are forgotten, so
+// that a nested Guard, such as Handle under Dispatch, leaves both intact.
+func endCall() {
+	calls--
+	if calls > 0 {
+		return
+	}
+	freedBlocks = nil
+	ResetMemoryPool()
+}
+
+// clearMemoryPool forgets the pooled and freed blocks without freeing them,
+// for when the host memory they refer to is gone
+func clearMemoryPool() {
+	freedBlocks = nil
+	memoryPool.free = nil
+	memoryPool.classes = nil
+}
+
+// sizeClass returns the pool size class for a block of length bytes
+func sizeClass(length uint64) uint64 {
+	class := uint64(minSizeClass)
+	for class < length {
+		class *= 2
+	}
+	return class
+}
+
+// allocate allocates length bytes of host memory, reusing a pooled block if
+// the pool is enabled, a call is in progress and the pool has one of the
+// right size class
+func allocate(length uint64) uint64 {
+	offset := allocateBlock(length)
+	delete(freedBlocks, offset)
+	return offset
+}
+
+// allocateBlock allocates a block for allocate, from the pool if possible
+func allocateBlock(length uint64) uint64 {
+	if !memoryPool.enabled || calls == 0 || length > maxPooledSize {
+		return extism_alloc(length)
+	}
+
+	class := sizeClass(length)
+	if offsets := memoryPool.free[class]; len(offsets) > 0 {
+		offset := offsets[len(offsets)-1]
+		memoryPool.free[class] = offsets[:len(offsets)-1]
+		return offset
+	}
+
+	// Allocate the whole size class so the block can be reused for any
+	// length in it
+	offset := extism_alloc(class)
+	if offset == 0 {
+		return 0
+	}
+	if memoryPool.classes == nil {
+		memoryPool.classes = map[uint64]uint64{}
+	}
+	memoryPool.classes[offset] = class
+	return offset
+}
+
+// release frees a block of host memory, returning it to the pool if the
+// pool allocated it and a call is in progress. Offset 0 and blocks already
+// freed during the call are ignored.
+func release(offset uint64) {
+	if offset == 0 || freedBlocks[offset] {
+		return
+	}
+	if calls > 0 {
+		if freedBlocks == nil {
+			freedBlocks = map[uint64]bool{}
+		}
+		freedBlocks[offset] = true
+	}
+
+	class, ok := memoryPool.classes[offset]
+	if !ok || !memoryPool.enabled || calls == 0 {
+		extism_free(offset)
+		return
+	}
+
+	if memoryPool.free == nil {
+		memoryPool.free = map[uint64][]uint64{}
+	}
+	memoryPool.free[class] = append(memoryPool.free[class], offset)
+}
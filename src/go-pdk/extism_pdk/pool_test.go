@@ -0,0 +1,125 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+// usePool enables the memory pool for the duration of a test or benchmark
+func usePool(tb testing.TB) {
+	extism_pdk.EnableMemoryPool(true)
+	tb.Cleanup(func() { extism_pdk.EnableMemoryPool(false) })
+}
+
+// logLoop logs n small messages, each allocating and freeing a block
+func logLoop(h extism_pdk.Host, n int) {
+	for i := 0; i < n; i++ {
+		h.LogInfo("message")
+	}
+}
+
+func TestMemoryPoolReusesBlocksInGuard(t *testing.T) {
+	usePool(t)
+	m := &testhost.MockHost{}
+	h := m.Host()
+
+	extism_pdk.Guard(func() int32 {
+		logLoop(h, 100)
+		return 0
+	})
+	if m.Allocs != 1 {
+		t.Fatalf("Allocs = %d, want 1", m.Allocs)
+	}
+	if len(m.Frees) != 1 {
+		t.Fatalf("Frees = %v, want the pooled block freed once when Guard returns", m.Frees)
+	}
+}
+
+func TestMemoryPoolKeptAcrossNestedGuard(t *testing.T) {
+	usePool(t)
+	m := &testhost.MockHost{}
+	h := m.Host()
+
+	extism_pdk.Guard(func() int32 {
+		h.LogInfo("before")
+		extism_pdk.Guard(func() int32 { return 0 })
+		if len(m.Frees) != 0 {
+			t.Errorf("Frees = %v, want the pool kept until the outer Guard returns", m.Frees)
+		}
+		h.LogInfo("after")
+		return 0
+	})
+	if m.Allocs != 1 || len(m.Frees) != 1 {
+		t.Fatalf("Allocs = %d, Frees = %v, want one block reused and freed once", m.Allocs, m.Frees)
+	}
+}
+
+func TestMemoryPoolUnusedOutsideGuard(t *testing.T) {
+	usePool(t)
+	m := &testhost.MockHost{}
+	logLoop(m.Host(), 100)
+	if m.Allocs != 100 || len(m.Frees) != 100 {
+		t.Fatalf("Allocs = %d, Frees = %d, want 100 each", m.Allocs, len(m.Frees))
+	}
+}
+
+func TestMemoryPoolForgottenWithStubHost(t *testing.T) {
+	usePool(t)
+	first := &testhost.MockHost{}
+	second := &testhost.MockHost{}
+
+	extism_pdk.Guard(func() int32 {
+		first.Host().LogInfo("first")
+		// The second host's memory doesn't hold the first host's pooled
+		// block, so it must not be handed out
+		second.Host().LogInfo("second")
+		return 0
+	})
+	if len(second.CapturedLogs) != 1 || second.CapturedLogs[0].Message != "second" {
+		t.Fatalf("CapturedLogs = %v", second.CapturedLogs)
+	}
+	if second.Allocs != 1 {
+		t.Fatalf("Allocs = %d, want 1", second.Allocs)
+	}
+}
+
+func TestHandleEmptiesMemoryPool(t *testing.T) {
+	usePool(t)
+	m := &testhost.MockHost{Input: []byte(`{"name":"Ada"}`)}
+	extism_pdk.Handle(m, func(req greetRequest) (greetResponse, error) {
+		m.LogInfo("greeting " + req.Name)
+		return greet(req)
+	})
+	if got := m.Allocs - 1; len(m.Frees) != got {
+		t.Fatalf("Frees = %v, want every block but the output freed (%d)", m.Frees, got)
+	}
+}
+
+// benchmarkLogLoop measures host allocations for a call logging 100 small
+// messages
+func benchmarkLogLoop(b *testing.B) {
+	m := &testhost.MockHost{}
+	h := m.Host()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		extism_pdk.Guard(func() int32 {
+			logLoop(h, 100)
+			return 0
+		})
+		m.CapturedLogs = m.CapturedLogs[:0]
+	}
+	b.ReportMetric(float64(m.Allocs)/float64(b.N), "host-allocs/op")
+}
+
+func BenchmarkLogLoopWithoutPool(b *testing.B) {
+	benchmarkLogLoop(b)
+}
+
+func BenchmarkLogLoopWithPool(b *testing.B) {
+	usePool(b)
+	benchmarkLogLoop(b)
+}
@@ -0,0 +1,53 @@
+// Package proto provides Protocol Buffers input and output helpers for
+// Extism plugins. It lives outside the core extism_pdk package so that only
+// plugins which import it depend on google.golang.org/protobuf.
+//
+// Importing the package registers its decoder and encoder with
+// extism_pdk.RegisterInputDecoder and extism_pdk.RegisterOutputEncoder, so
+// Host.DecodeInput and Host.RespondNegotiated handle protobuf for values
+// that are proto.Message.
+package proto
+
+import (
+	"fmt"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"google.golang.org/protobuf/proto"
+)
+
+func init() {
+	decode := func(h extism_pdk.Host, v interface{}) error {
+		m, ok := v.(proto.Message)
+		if !ok {
+			return fmt.Errorf("protobuf input requires a proto.Message, got %T", v)
+		}
+		return GetInputProto(h, m)
+	}
+	encode := func(h extism_pdk.Host, v interface{}) error {
+		m, ok := v.(proto.Message)
+		if !ok {
+			return fmt.Errorf("protobuf output requires a proto.Message, got %T", v)
+		}
+		return SetOutputProto(h, m)
+	}
+	for _, mediaType := range []string{"application/protobuf", "application/x-protobuf", "application/vnd.google.protobuf"} {
+		extism_pdk.RegisterInputDecoder(mediaType, decode)
+		extism_pdk.RegisterOutputEncoder(mediaType, encode)
+	}
+}
+
+// GetInputProto unmarshals the protobuf input into the provided message. It
+// mirrors HostAPI.GetInputJSON.
+func GetInputProto(host extism_pdk.HostAPI, m proto.Message) error {
+	return proto.Unmarshal(host.GetInput(), m)
+}
+
+// SetOutputProto marshals the provided message to protobuf and sets it as
+// output. It mirrors HostAPI.SetOutputJSON.
+func SetOutputProto(host extism_pdk.HostAPI, m proto.Message) error {
+	data, err := proto.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return host.SetOutput(data)
+}
@@ -0,0 +1,64 @@
+//go:build !tinygo && !wasip1
+
+package proto_test
+
+import (
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	pdkproto "github.com/louloulin/Extismx/src/go-pdk/extism_pdk/proto"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func newMessage(t *testing.T) *structpb.Struct {
+	t.Helper()
+	m, err := structpb.NewStruct(map[string]interface{}{
+		"name":  "sensor-1",
+		"count": 3,
+		"tags":  []interface{}{"a", "b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := newMessage(t)
+
+	m := &testhost.MockHost{}
+	if err := pdkproto.SetOutputProto(m, in); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Input = m.Output
+	out := &structpb.Struct{}
+	if err := pdkproto.GetInputProto(m, out); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(in, out) {
+		t.Fatalf("round trip = %v, want %v", out, in)
+	}
+}
+
+func TestDecodeInput(t *testing.T) {
+	in := newMessage(t)
+	data, err := proto.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &testhost.MockHost{
+		Input:  data,
+		Config: map[string]string{extism_pdk.InputContentTypeKey: "application/x-protobuf"},
+	}
+	out := &structpb.Struct{}
+	if err := m.Host().DecodeInput(out); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(in, out) {
+		t.Fatalf("decoded %v, want %v", out, in)
+	}
+}
@@ -0,0 +1,42 @@
+package extism_pdk
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// RandomHostFunc is the name under which a host random function can be
+// registered with RegisterHostFunc. It takes the number of bytes wanted as a
+// decimal string and returns that many random bytes.
+const RandomHostFunc = "extism_random_bytes"
+
+// RandomBytes returns n random bytes from the RandomHostFunc host function.
+// There is no fallback: if the plugin hasn't registered the host function,
+// an error wrapping ErrHostFuncNotFound is returned rather than predictable
+// bytes.
+func (h Host) RandomBytes(n int) ([]byte, error) {
+	if n <= 0 {
+		return []byte{}, nil
+	}
+
+	result, err := CallHostFunc(RandomHostFunc, []byte(strconv.Itoa(n)))
+	if err != nil {
+		return nil, err
+	}
+	if len(result) != n {
+		return nil, fmt.Errorf("host function %q returned %d bytes, want %d", RandomHostFunc, len(result), n)
+	}
+	return result, nil
+}
+
+// NewUUID returns a random (version 4) UUID in its canonical string form,
+// using RandomBytes
+func (h Host) NewUUID() (string, error) {
+	b, err := h.RandomBytes(16)
+	if err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,49 @@
+package extism_pdk
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Recover converts a panic into a plugin error instead of letting it trap the
+// module. It must be deferred directly at the top of an exported function:
+//
+//	defer host.Recover()
+//
+// The function still returns its current result, so use Guard when a
+// non-zero return code is needed.
+func (h Host) Recover() {
+	if r := recover(); r != nil {
+		reportPanic(h, r)
+	}
+}
+
+// Guard runs an entrypoint, converting any panic into a plugin error and a
+// return code of 1. It flushes buffered logs and empties the memory pool
+// before returning.
+func Guard(fn func() int32) int32 {
+	return guard(CreateHost(), fn)
+}
+
+// guard implements Guard, reporting a panic to h
+func guard(h HostAPI, fn func() int32) (code int32) {
+	beginCall()
+	defer endCall()
+	defer flushLogs()
+	defer func() {
+		if r := recover(); r != nil {
+			reportPanic(h, r)
+			code = 1
+		}
+	}()
+	return fn()
+}
+
+// reportPanic logs a recovered panic value with its stack and sets it as the
+// plugin error. Recover and Guard, and so Dispatch and Handle, all report
+// panics this way.
+func reportPanic(h HostAPI, r interface{}) {
+	msg := fmt.Sprintf("panic: %v", r)
+	h.LogError(msg + "\n" + string(debug.Stack()))
+	h.SetError(msg)
+}
@@ -0,0 +1,31 @@
+package extism_pdk
+
+// SecretHostFunc is the name under which a host secret store function can be
+// registered with RegisterHostFunc. It takes a key and returns the secret's
+// value, or no result if the secret is absent.
+const SecretHostFunc = "extism_secret_get"
+
+// secretFallbackWarned records that GetSecret has warned about falling back
+// to configuration
+var secretFallbackWarned bool
+
+// GetSecret gets a secret by key, reporting whether it is present. It reads
+// from the host's secret store through SecretHostFunc if the plugin
+// registered it. Otherwise it falls back to the configuration value of the
+// same name, logging a warning (without the value) the first time, since
+// the host may not protect configuration like secrets.
+func (h Host) GetSecret(key string) (string, bool) {
+	if _, ok := hostFuncs[SecretHostFunc]; !ok {
+		if !secretFallbackWarned {
+			secretFallbackWarned = true
+			h.LogWarnf("no %s host function registered; reading secrets from config", SecretHostFunc)
+		}
+		return h.lookupConfig(key)
+	}
+
+	value, err := CallHostFunc(SecretHostFunc, []byte(key))
+	if err != nil || value == nil {
+		return "", false
+	}
+	return string(value), true
+}
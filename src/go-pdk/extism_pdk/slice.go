@@ -0,0 +1,30 @@
+package extism_pdk
+
+import "encoding/json"
+
+// GetInputSlice decodes an input JSON array into a slice of T, streaming it
+// from the host. An empty input returns ErrNoInput.
+func GetInputSlice[T any](h Host) ([]T, error) {
+	if h.InputLength() == 0 {
+		return nil, ErrNoInput
+	}
+
+	var items []T
+	if err := json.NewDecoder(h.InputReader()).Decode(&items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SetOutputSlice sets items as a JSON array output, streaming each element
+// into host memory with a JSONArrayWriter. A nil slice is output as [].
+func SetOutputSlice[T any](h Host, items []T) error {
+	w := h.NewJSONArrayOutput()
+	for _, item := range items {
+		if err := w.Append(item); err != nil {
+			w.out.discard()
+			return err
+		}
+	}
+	return w.Close()
+}
@@ -0,0 +1,195 @@
+package extism_pdk
+
+import "io"
+
+// minHostBufferSize is the initial capacity of a hostBuffer
+const minHostBufferSize = 4096
+
+// hostBuffer is a growable buffer kept in host memory rather than Go memory.
+// When it fills up its contents are copied into a block of twice the size,
+// so appends cost amortized constant time.
+type hostBuffer struct {
+	offset   uint64
+	length   uint64
+	capacity uint64
+}
+
+// grow makes room for at least n more bytes
+func (b *hostBuffer) grow(n uint64) error {
+	needed := b.length + n
+	if needed <= b.capacity {
+		return nil
+	}
+
+	capacity := b.capacity * 2
+	if capacity < minHostBufferSize {
+		capacity = minHostBufferSize
+	}
+	if capacity < needed {
+		capacity = needed
+	}
+
+	offset := allocate(capacity)
+	if offset == 0 {
+		return ErrAllocFailed
+	}
+	if b.offset != 0 {
+		copyMemory(offset, b.offset, b.length)
+		release(b.offset)
+	}
+	b.offset = offset
+	b.capacity = capacity
+	return nil
+}
+
+// write appends p to the buffer
+func (b *hostBuffer) write(p []byte) error {
+	if err := b.grow(uint64(len(p))); err != nil {
+		return err
+	}
+	writeMemory(b.offset+b.length, p)
+	b.length += uint64(len(p))
+	return nil
+}
+
+// hostBufferWriter adapts a hostBuffer to io.Writer
+type hostBufferWriter struct {
+	buf *hostBuffer
+}
+
+// Write implements io.Writer
+func (w hostBufferWriter) Write(p []byte) (int, error) {
+	if err := w.buf.write(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// free releases the buffer's host memory
+func (b *hostBuffer) free() {
+	if b.offset != 0 {
+		release(b.offset)
+	}
+	*b = hostBuffer{}
+}
+
+// OutputStream writes the plugin output piecewise into host memory. Create
+// one with BeginOutput, write to it, then call Commit to set the output.
+//
+// The data goes straight to host memory, so the plugin never holds the whole
+// output in Go memory. The host buffer doubles in size as it fills, copying
+// its contents each time; while growing, the old and new blocks are both
+// allocated, so the host briefly needs up to three times the output size.
+type OutputStream struct {
+	host      Host
+	buf       hostBuffer
+	committed bool
+}
+
+// BeginOutput starts streaming the plugin output into host memory, returning
+// an error if the host can't allocate the initial buffer
+func (h Host) BeginOutput() (*OutputStream, error) {
+	s := &OutputStream{host: h}
+	if err := s.buf.grow(minHostBufferSize); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Write implements io.Writer, appending p to the output. If the host can't
+// grow the buffer, Write returns ErrAllocFailed and nothing is written.
+func (s *OutputStream) Write(p []byte) (int, error) {
+	if s.committed {
+		return 0, ErrWriterClosed
+	}
+	if err := s.buf.write(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Len returns the number of bytes written so far
+func (s *OutputStream) Len() uint64 {
+	return s.buf.length
+}
+
+// Commit sets the written data as the plugin output, passing ownership of
+// the buffer to the host. The stream can't be used afterwards.
+func (s *OutputStream) Commit() error {
+	if s.committed {
+		return ErrWriterClosed
+	}
+	s.committed = true
+	flushLogs()
+	extism_output_set(s.buf.offset, s.buf.length)
+	s.buf = hostBuffer{}
+	return nil
+}
+
+// Discard frees the buffer without setting the output. It does nothing
+// after Commit.
+func (s *OutputStream) Discard() {
+	if s.committed {
+		return
+	}
+	s.committed = true
+	s.buf.free()
+}
+
+// SetOutputFrom reads r to the end into host memory through an
+// OutputStream and sets it as the output, returning the number of bytes
+// copied. If reading fails, the error is returned and no output is set.
+func (h Host) SetOutputFrom(r io.Reader) (int64, error) {
+	stream, err := h.BeginOutput()
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(stream, r)
+	if err != nil {
+		stream.Discard()
+		return n, err
+	}
+	return n, stream.Commit()
+}
+
+// lazyOutput is an OutputStream started on first use, recording the first
+// error so that writers built on it fail consistently
+type lazyOutput struct {
+	host   Host
+	stream *OutputStream
+	err    error
+}
+
+// write appends data to the stream, starting it if needed
+func (o *lazyOutput) write(data []byte) error {
+	if o.err != nil {
+		return o.err
+	}
+	if o.stream == nil {
+		o.stream, o.err = o.host.BeginOutput()
+		if o.err != nil {
+			return o.err
+		}
+	}
+	if _, err := o.stream.Write(data); err != nil {
+		o.err = err
+	}
+	return o.err
+}
+
+// commit sets the stream as the output, setting an empty output if nothing
+// was written
+func (o *lazyOutput) commit() error {
+	if o.stream == nil {
+		return o.host.SetOutput(nil)
+	}
+	return o.stream.Commit()
+}
+
+// discard frees the stream without setting the output
+func (o *lazyOutput) discard() {
+	if o.stream != nil {
+		o.stream.Discard()
+	}
+}
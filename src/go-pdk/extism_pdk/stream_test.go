@@ -0,0 +1,77 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+func TestOutputStream(t *testing.T) {
+	m := &testhost.MockHost{}
+	stream, err := m.Host().BeginOutput()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Write enough to grow the host buffer several times
+	var want bytes.Buffer
+	for i := 0; i < 100; i++ {
+		chunk := bytes.Repeat([]byte{byte('a' + i%26)}, 1000)
+		want.Write(chunk)
+		if _, err := stream.Write(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stream.Len() != uint64(want.Len()) {
+		t.Fatalf("Len = %d, want %d", stream.Len(), want.Len())
+	}
+	if err := stream.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(m.Output, want.Bytes()) {
+		t.Fatalf("Output has %d bytes, want %d", len(m.Output), want.Len())
+	}
+
+	if _, err := stream.Write([]byte("late")); !errors.Is(err, extism_pdk.ErrWriterClosed) {
+		t.Fatalf("Write after Commit err = %v", err)
+	}
+	if err := stream.Commit(); !errors.Is(err, extism_pdk.ErrWriterClosed) {
+		t.Fatalf("second Commit err = %v", err)
+	}
+}
+
+func TestOutputStreamDiscard(t *testing.T) {
+	m := &testhost.MockHost{}
+	stream, err := m.Host().BeginOutput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Write([]byte("draft"))
+	stream.Discard()
+
+	if m.Output != nil {
+		t.Fatalf("Output = %q after Discard", m.Output)
+	}
+	if len(m.Frees) != 1 {
+		t.Fatalf("Frees = %v, want the buffer freed", m.Frees)
+	}
+}
+
+func TestSetOutputFrom(t *testing.T) {
+	m := &testhost.MockHost{}
+	input := strings.Repeat("streamed ", 2000)
+
+	n, err := m.Host().SetOutputFrom(strings.NewReader(input))
+	if err != nil || n != int64(len(input)) {
+		t.Fatalf("n = %d, err = %v", n, err)
+	}
+	if string(m.Output) != input {
+		t.Fatalf("Output has %d bytes, want %d", len(m.Output), len(input))
+	}
+}
@@ -0,0 +1,226 @@
+//go:build !tinygo && !wasip1
+
+// Package testhost provides an in-memory implementation of
+// extism_pdk.HostAPI for unit testing plugins without a WASM runtime
+package testhost
+
+import (
+	"fmt"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+)
+
+// LogEntry is a log message captured by MockHost
+type LogEntry struct {
+	Level   extism_pdk.LogLevel
+	Message string
+}
+
+// MockHost is an in-memory HostAPI. Set Input, Config, Vars and
+// HTTPResponses before calling the plugin function, then inspect Output,
+// Error, CapturedLogs and HTTPRequests.
+//
+// MockHost serves the extism_pdk native host function stubs, and its methods
+// run the real extism_pdk.Host implementation against them. Use Host to call
+// Host methods that are not part of HostAPI.
+type MockHost struct {
+	Input  []byte
+	Output []byte
+	Error  string
+	Config map[string]string
+	Vars   map[string]string
+
+	// HTTPResponses maps request URLs to the responses HTTP returns for
+	// them. Requests to other URLs fail.
+	HTTPResponses map[string]*extism_pdk.HTTPResponse
+	// HTTPRequests records every request passed to HTTP
+	HTTPRequests []extism_pdk.HTTPRequest
+
+	CapturedLogs []LogEntry
+
+	// FailAlloc makes every host memory allocation by the plugin fail
+	FailAlloc bool
+
+	// Allocs counts the blocks of host memory the plugin allocated and
+	// AllocBytes their total length, and Frees records the offsets of the
+	// blocks it freed, in order
+	Allocs     int
+	AllocBytes uint64
+	Frees      []uint64
+}
+
+var _ extism_pdk.HostAPI = (*MockHost)(nil)
+
+// Host installs the mock behind the extism_pdk native host function stubs
+// and returns an extism_pdk.Host backed by it
+func (m *MockHost) Host() extism_pdk.Host {
+	extism_pdk.UseStubHost(stubHost{m})
+	return extism_pdk.CreateHost()
+}
+
+// StubHTTP registers the response returned for requests to url
+func (m *MockHost) StubHTTP(url string, resp *extism_pdk.HTTPResponse) {
+	if m.HTTPResponses == nil {
+		m.HTTPResponses = map[string]*extism_pdk.HTTPResponse{}
+	}
+	m.HTTPResponses[url] = resp
+}
+
+// StubHostFunc registers fn as the custom host function name, for plugins
+// using extism_pdk.CallHostFunc. A nil result is returned to the plugin as
+// no result.
+func (m *MockHost) StubHostFunc(name string, fn func(input []byte) []byte) {
+	extism_pdk.RegisterHostFunc(name, func(offset uint64, length uint64) uint64 {
+		output := fn(extism_pdk.NewMemory(offset, length).Load())
+		if output == nil {
+			return 0
+		}
+		return extism_pdk.AllocateMemory(output).Offset()
+	})
+}
+
+// GetInput returns the mock input
+func (m *MockHost) GetInput() []byte {
+	return m.Host().GetInput()
+}
+
+// GetInputString returns the mock input as a string
+func (m *MockHost) GetInputString() string {
+	return m.Host().GetInputString()
+}
+
+// GetInputJSON unmarshals the mock input JSON into the provided interface
+func (m *MockHost) GetInputJSON(v interface{}) error {
+	return m.Host().GetInputJSON(v)
+}
+
+// SetOutput records the output data
+func (m *MockHost) SetOutput(data []byte) error {
+	return m.Host().SetOutput(data)
+}
+
+// SetOutputString records the output string
+func (m *MockHost) SetOutputString(s string) error {
+	return m.Host().SetOutputString(s)
+}
+
+// SetOutputJSON marshals the provided interface to JSON and records it as
+// output
+func (m *MockHost) SetOutputJSON(v interface{}) error {
+	return m.Host().SetOutputJSON(v)
+}
+
+// SetError records the error message
+func (m *MockHost) SetError(msg string) error {
+	return m.Host().SetError(msg)
+}
+
+// LogInfo captures an informational message
+func (m *MockHost) LogInfo(msg string) {
+	m.Host().LogInfo(msg)
+}
+
+// LogDebug captures a debug message
+func (m *MockHost) LogDebug(msg string) {
+	m.Host().LogDebug(msg)
+}
+
+// LogWarn captures a warning message
+func (m *MockHost) LogWarn(msg string) {
+	m.Host().LogWarn(msg)
+}
+
+// LogError captures an error message
+func (m *MockHost) LogError(msg string) {
+	m.Host().LogError(msg)
+}
+
+// HTTP records the request and returns the response stubbed for its URL
+func (m *MockHost) HTTP(req extism_pdk.HTTPRequest) (*extism_pdk.HTTPResponse, error) {
+	return m.Host().HTTP(req)
+}
+
+// GetConfig gets a configuration value from Config
+func (m *MockHost) GetConfig(key string) string {
+	return m.Host().GetConfig(key)
+}
+
+// GetVar gets a variable value from Vars
+func (m *MockHost) GetVar(key string) string {
+	return m.Host().GetVar(key)
+}
+
+// SetVar stores a variable value in Vars
+func (m *MockHost) SetVar(key string, value string) bool {
+	return m.Host().SetVar(key, value)
+}
+
+// DeleteVar removes a variable from Vars
+func (m *MockHost) DeleteVar(key string) bool {
+	return m.Host().DeleteVar(key)
+}
+
+// stubHost adapts a MockHost to extism_pdk.StubHost
+type stubHost struct {
+	m *MockHost
+}
+
+func (s stubHost) Input() []byte {
+	return s.m.Input
+}
+
+func (s stubHost) SetOutput(data []byte) {
+	s.m.Output = data
+}
+
+func (s stubHost) SetError(msg string) {
+	s.m.Error = msg
+}
+
+func (s stubHost) Config(key string) (string, bool) {
+	value, ok := s.m.Config[key]
+	return value, ok
+}
+
+func (s stubHost) Var(key string) ([]byte, bool) {
+	value, ok := s.m.Vars[key]
+	return []byte(value), ok
+}
+
+func (s stubHost) SetVar(key string, value []byte) {
+	if s.m.Vars == nil {
+		s.m.Vars = map[string]string{}
+	}
+	s.m.Vars[key] = string(value)
+}
+
+func (s stubHost) DeleteVar(key string) {
+	delete(s.m.Vars, key)
+}
+
+func (s stubHost) Log(level extism_pdk.LogLevel, msg string) {
+	s.m.CapturedLogs = append(s.m.CapturedLogs, LogEntry{Level: level, Message: msg})
+}
+
+func (s stubHost) FailAlloc() bool {
+	return s.m.FailAlloc
+}
+
+func (s stubHost) Allocated(offset uint64, length uint64) {
+	s.m.Allocs++
+	s.m.AllocBytes += length
+}
+
+func (s stubHost) Freed(offset uint64) {
+	s.m.Frees = append(s.m.Frees, offset)
+}
+
+func (s stubHost) HTTP(req extism_pdk.HTTPRequest) (*extism_pdk.HTTPResponse, error) {
+	s.m.HTTPRequests = append(s.m.HTTPRequests, req)
+
+	resp, ok := s.m.HTTPResponses[req.URL]
+	if !ok {
+		return nil, fmt.Errorf("no response stubbed for %s", req.URL)
+	}
+	return resp, nil
+}
@@ -0,0 +1,109 @@
+//go:build !tinygo && !wasip1
+
+package testhost_test
+
+import (
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+func TestMockHostInputOutput(t *testing.T) {
+	m := &testhost.MockHost{Input: []byte("in")}
+	if got := m.GetInputString(); got != "in" {
+		t.Fatalf("GetInputString = %q", got)
+	}
+	m.SetOutputString("out")
+	if string(m.Output) != "out" {
+		t.Fatalf("Output = %q", m.Output)
+	}
+	m.SetError("failed")
+	if m.Error != "failed" {
+		t.Fatalf("Error = %q", m.Error)
+	}
+}
+
+func TestMockHostConfigAndVars(t *testing.T) {
+	m := &testhost.MockHost{
+		Config: map[string]string{"region": "eu"},
+		Vars:   map[string]string{"count": "1"},
+	}
+	if got := m.GetConfig("region"); got != "eu" {
+		t.Fatalf("GetConfig = %q", got)
+	}
+	if got := m.GetVar("count"); got != "1" {
+		t.Fatalf("GetVar = %q", got)
+	}
+	m.SetVar("count", "2")
+	if m.Vars["count"] != "2" {
+		t.Fatalf("Vars = %v", m.Vars)
+	}
+}
+
+func TestMockHostCapturesLogs(t *testing.T) {
+	m := &testhost.MockHost{}
+	m.LogInfo("info")
+	m.LogError("error")
+
+	want := []testhost.LogEntry{
+		{Level: extism_pdk.LogLevelInfo, Message: "info"},
+		{Level: extism_pdk.LogLevelError, Message: "error"},
+	}
+	if len(m.CapturedLogs) != len(want) {
+		t.Fatalf("CapturedLogs = %v", m.CapturedLogs)
+	}
+	for i := range want {
+		if m.CapturedLogs[i] != want[i] {
+			t.Errorf("CapturedLogs[%d] = %v, want %v", i, m.CapturedLogs[i], want[i])
+		}
+	}
+}
+
+func TestMockHostHTTP(t *testing.T) {
+	m := &testhost.MockHost{}
+	m.StubHTTP("https://example.com/", &extism_pdk.HTTPResponse{Status: 200, Body: "hello"})
+
+	resp, err := m.HTTP(extism_pdk.HTTPRequest{Method: "GET", URL: "https://example.com/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != 200 || resp.Body != "hello" {
+		t.Fatalf("resp = %d %q", resp.Status, resp.Body)
+	}
+
+	if _, err := m.HTTP(extism_pdk.HTTPRequest{Method: "GET", URL: "https://example.com/other"}); err == nil {
+		t.Fatal("unstubbed URL succeeded")
+	}
+	if len(m.HTTPRequests) != 2 || m.HTTPRequests[1].URL != "https://example.com/other" {
+		t.Fatalf("HTTPRequests = %v", m.HTTPRequests)
+	}
+}
+
+func TestMockHostStubHostFunc(t *testing.T) {
+	m := &testhost.MockHost{}
+	m.Host()
+	m.StubHostFunc("upper", func(input []byte) []byte {
+		return []byte("UPPER:" + string(input))
+	})
+
+	out, err := extism_pdk.CallHostFunc("upper", []byte("x"))
+	if err != nil || string(out) != "UPPER:x" {
+		t.Fatalf("out = %q, err = %v", out, err)
+	}
+}
+
+func TestMockHostTracksAllocations(t *testing.T) {
+	m := &testhost.MockHost{}
+	m.Host()
+	mem := extism_pdk.AllocateMemory([]byte("data"))
+	extism_pdk.AllocateMemory([]byte("more data"))
+	mem.Free()
+
+	if m.Allocs != 2 || m.AllocBytes != 13 {
+		t.Fatalf("Allocs = %d, AllocBytes = %d, want 2 and 13", m.Allocs, m.AllocBytes)
+	}
+	if len(m.Frees) != 1 || m.Frees[0] != mem.Offset() {
+		t.Fatalf("Frees = %v, want [%d]", m.Frees, mem.Offset())
+	}
+}
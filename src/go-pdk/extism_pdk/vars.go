@@ -0,0 +1,218 @@
+package extism_pdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// lookupVar gets a variable value by key, reporting whether the key is
+// present
+func (h Host) lookupVar(key string) ([]byte, bool) {
+	mem, err := allocateMemory([]byte(key))
+	if err != nil {
+		return nil, false
+	}
+	defer mem.Free()
+
+	resultPtr := extism_var_get(mem.Offset(), mem.Length())
+	if resultPtr == 0 {
+		return nil, false
+	}
+
+	return findMemory(resultPtr).Load(), true
+}
+
+// GetVarBytes gets a binary variable value by key, returning nil if the key
+// is absent
+func (h Host) GetVarBytes(key string) []byte {
+	value, _ := h.lookupVar(key)
+	return value
+}
+
+// SetVarBytes sets a binary variable value by key. As with SetVar, an empty
+// value deletes the variable.
+func (h Host) SetVarBytes(key string, value []byte) bool {
+	keyMem, err := allocateMemory([]byte(key))
+	if err != nil {
+		return false
+	}
+	defer keyMem.Free()
+
+	valueMem, err := allocateMemory(value)
+	if err != nil {
+		return false
+	}
+	defer valueMem.Free()
+
+	result := extism_var_set(keyMem.Offset(), keyMem.Length(), valueMem.Offset(), valueMem.Length())
+	return result == 1
+}
+
+// HasVar reports whether a variable is present, even if its value is empty
+func (h Host) HasVar(key string) bool {
+	_, ok := h.lookupVar(key)
+	return ok
+}
+
+// DeleteVar removes a variable from host storage. Extism treats setting a
+// zero-length value as deletion.
+func (h Host) DeleteVar(key string) bool {
+	keyMem, err := allocateMemory([]byte(key))
+	if err != nil {
+		return false
+	}
+	defer keyMem.Free()
+
+	result := extism_var_set(keyMem.Offset(), keyMem.Length(), 0, 0)
+	return result == 1
+}
+
+// GetVarInt gets a variable value parsed as a base-10 integer. The second
+// return value is false if the key is absent or cannot be parsed.
+func (h Host) GetVarInt(key string) (int64, bool) {
+	value, err := strconv.ParseInt(h.GetVar(key), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// SetVarInt stores an integer variable in base 10
+func (h Host) SetVarInt(key string, value int64) bool {
+	return h.SetVar(key, strconv.FormatInt(value, 10))
+}
+
+// IncrVar adds delta to an integer variable, treating a missing variable as
+// 0, and returns the new value. The read and write are separate host calls,
+// so this is not atomic across concurrently running plugin instances; it is
+// correct within one instance, where calls run one at a time.
+func (h Host) IncrVar(key string, delta int64) (int64, error) {
+	var value int64
+	if data, ok := h.lookupVar(key); ok {
+		current, err := strconv.ParseInt(string(data), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("var %q is not an integer: %w", key, err)
+		}
+		value = current
+	}
+
+	value += delta
+	if !h.SetVarInt(key, value) {
+		return 0, fmt.Errorf("%w %q", ErrVarSetFailed, key)
+	}
+	return value, nil
+}
+
+// GetVarJSON unmarshals a JSON variable into the provided interface
+func (h Host) GetVarJSON(key string, v interface{}) error {
+	data, ok := h.lookupVar(key)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrVarMissing, key)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// SetVarJSON marshals the provided interface to JSON and stores it as a
+// variable
+func (h Host) SetVarJSON(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if !h.SetVarBytes(key, data) {
+		return fmt.Errorf("%w %q", ErrVarSetFailed, key)
+	}
+	return nil
+}
+
+// LoadState unmarshals the JSON state stored under key into v. It returns
+// false with no error if the key is absent, leaving v untouched, and an
+// error if the stored value is not valid JSON for v.
+func (h Host) LoadState(key string, v interface{}) (bool, error) {
+	data, ok := h.lookupVar(key)
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return true, fmt.Errorf("invalid state in var %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// SaveState stores v as JSON state under key, for LoadState to read in a
+// later invocation
+func (h Host) SaveState(key string, v interface{}) error {
+	return h.SetVarJSON(key, v)
+}
+
+// chunkKey returns the variable holding chunk i of a chunked value
+func chunkKey(key string, i int) string {
+	return key + "." + strconv.Itoa(i)
+}
+
+// chunkCountKey returns the variable holding the chunk count of a chunked
+// value
+func chunkCountKey(key string) string {
+	return key + ".__count"
+}
+
+// SetVarChunked stores a value too large for a single variable by splitting
+// it into chunks of at most chunkSize bytes, stored in key.0, key.1, ...,
+// with the number of chunks in key.__count. Chunks left over from a longer
+// previous value are deleted. The chunks are written before the count, but
+// the update is not atomic: a failure part way can leave a mix of old and
+// new chunks.
+func (h Host) SetVarChunked(key string, value []byte, chunkSize int) error {
+	if chunkSize <= 0 {
+		return fmt.Errorf("invalid chunk size %d", chunkSize)
+	}
+
+	previous, _ := h.GetVarInt(chunkCountKey(key))
+
+	count := 0
+	for offset := 0; offset < len(value); offset += chunkSize {
+		end := offset + chunkSize
+		if end > len(value) {
+			end = len(value)
+		}
+		if !h.SetVarBytes(chunkKey(key, count), value[offset:end]) {
+			return fmt.Errorf("%w %q", ErrVarSetFailed, chunkKey(key, count))
+		}
+		count++
+	}
+
+	if !h.SetVarInt(chunkCountKey(key), int64(count)) {
+		return fmt.Errorf("%w %q", ErrVarSetFailed, chunkCountKey(key))
+	}
+	for i := count; i < int(previous); i++ {
+		h.DeleteVar(chunkKey(key, i))
+	}
+	return nil
+}
+
+// GetVarChunked reassembles a value stored with SetVarChunked, returning an
+// error wrapping ErrVarMissing if the value or any of its chunks is absent
+func (h Host) GetVarChunked(key string) ([]byte, error) {
+	data, ok := h.lookupVar(chunkCountKey(key))
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrVarMissing, chunkCountKey(key))
+	}
+	count, err := strconv.Atoi(string(data))
+	if err != nil || count < 0 {
+		return nil, fmt.Errorf("invalid chunk count in var %q", chunkCountKey(key))
+	}
+
+	var value []byte
+	for i := 0; i < count; i++ {
+		chunk, ok := h.lookupVar(chunkKey(key, i))
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrVarMissing, chunkKey(key, i))
+		}
+		value = append(value, chunk...)
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	return value, nil
+}
@@ -0,0 +1,113 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+func TestDeleteVar(t *testing.T) {
+	m := &testhost.MockHost{Vars: map[string]string{"scratch": "value"}}
+	h := m.Host()
+
+	if !h.DeleteVar("scratch") {
+		t.Fatal("DeleteVar failed")
+	}
+	if got := h.GetVar("scratch"); got != "" {
+		t.Fatalf("GetVar after delete = %q", got)
+	}
+	if h.HasVar("scratch") {
+		t.Fatal("HasVar after delete = true")
+	}
+}
+
+func TestIncrVar(t *testing.T) {
+	m := &testhost.MockHost{Vars: map[string]string{"existing": "40", "text": "abc"}}
+	h := m.Host()
+
+	if n, err := h.IncrVar("missing", 3); err != nil || n != 3 {
+		t.Errorf("IncrVar(missing) = %d, %v, want 3", n, err)
+	}
+	if n, err := h.IncrVar("existing", 2); err != nil || n != 42 {
+		t.Errorf("IncrVar(existing) = %d, %v, want 42", n, err)
+	}
+	if m.Vars["missing"] != "3" || m.Vars["existing"] != "42" {
+		t.Errorf("Vars = %v", m.Vars)
+	}
+	if _, err := h.IncrVar("text", 1); err == nil {
+		t.Error("IncrVar of a non-integer succeeded")
+	}
+}
+
+type counterState struct {
+	Count int      `json:"count"`
+	Seen  []string `json:"seen"`
+}
+
+func TestLoadState(t *testing.T) {
+	m := &testhost.MockHost{Vars: map[string]string{"corrupt": `{"count":`}}
+	h := m.Host()
+
+	var state counterState
+	if ok, err := h.LoadState("missing", &state); ok || err != nil {
+		t.Errorf("missing: ok = %v, err = %v", ok, err)
+	}
+
+	want := counterState{Count: 2, Seen: []string{"a", "b"}}
+	if err := h.SaveState("valid", want); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := h.LoadState("valid", &state); !ok || err != nil || !reflect.DeepEqual(state, want) {
+		t.Errorf("valid: ok = %v, err = %v, state = %+v", ok, err, state)
+	}
+
+	if ok, err := h.LoadState("corrupt", &state); !ok || err == nil {
+		t.Errorf("corrupt: ok = %v, err = %v, want an error", ok, err)
+	}
+}
+
+func TestVarChunkedRoundTrip(t *testing.T) {
+	m := &testhost.MockHost{}
+	h := m.Host()
+	value := []byte("0123456789abcdefghij")
+
+	if err := h.SetVarChunked("blob", value, 8); err != nil {
+		t.Fatal(err)
+	}
+	if m.Vars["blob.__count"] != "3" || m.Vars["blob.0"] != "01234567" || m.Vars["blob.2"] != "ghij" {
+		t.Fatalf("Vars = %v", m.Vars)
+	}
+	got, err := h.GetVarChunked("blob")
+	if err != nil || !bytes.Equal(got, value) {
+		t.Fatalf("GetVarChunked = %q, %v", got, err)
+	}
+
+	// A shorter value removes the chunks it no longer needs
+	if err := h.SetVarChunked("blob", []byte("short"), 8); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.Vars["blob.1"]; ok {
+		t.Fatalf("stale chunk left: %v", m.Vars)
+	}
+	if got, err := h.GetVarChunked("blob"); err != nil || string(got) != "short" {
+		t.Fatalf("GetVarChunked = %q, %v", got, err)
+	}
+}
+
+func TestGetVarChunkedMissing(t *testing.T) {
+	m := &testhost.MockHost{Vars: map[string]string{"blob.__count": "2", "blob.0": "a"}}
+	h := m.Host()
+
+	if _, err := h.GetVarChunked("absent"); !errors.Is(err, extism_pdk.ErrVarMissing) {
+		t.Errorf("absent: err = %v, want ErrVarMissing", err)
+	}
+	if _, err := h.GetVarChunked("blob"); !errors.Is(err, extism_pdk.ErrVarMissing) {
+		t.Errorf("missing chunk: err = %v, want ErrVarMissing", err)
+	}
+}
@@ -0,0 +1,31 @@
+package extism_pdk
+
+import "encoding/xml"
+
+// GetInputXML decodes the input XML into the provided interface, streaming
+// it from the host
+func (h Host) GetInputXML(v interface{}) error {
+	if h.InputLength() == 0 {
+		return ErrNoInput
+	}
+	return xml.NewDecoder(h.InputReader()).Decode(v)
+}
+
+// SetOutputXML marshals the provided interface to XML and sets it as output
+func (h Host) SetOutputXML(v interface{}) error {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return h.SetOutput(data)
+}
+
+// SetOutputXMLWithHeader marshals the provided interface to XML and sets it
+// as output, preceded by the standard XML declaration (xml.Header)
+func (h Host) SetOutputXMLWithHeader(v interface{}) error {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return h.SetOutput(append([]byte(xml.Header), data...))
+}
@@ -0,0 +1,57 @@
+//go:build !tinygo && !wasip1
+
+package extism_pdk_test
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk/testhost"
+)
+
+type order struct {
+	XMLName xml.Name `xml:"order"`
+	ID      string   `xml:"id,attr"`
+	Items   []item   `xml:"items>item"`
+}
+
+type item struct {
+	SKU      string `xml:"sku,attr"`
+	Quantity int    `xml:"quantity"`
+}
+
+func TestXMLRoundTrip(t *testing.T) {
+	in := order{ID: "42", Items: []item{{SKU: "a-1", Quantity: 2}, {SKU: "b-7", Quantity: 1}}}
+
+	m := &testhost.MockHost{}
+	h := m.Host()
+	if err := h.SetOutputXML(in); err != nil {
+		t.Fatal(err)
+	}
+	want := `<order id="42"><items><item sku="a-1"><quantity>2</quantity></item><item sku="b-7"><quantity>1</quantity></item></items></order>`
+	if string(m.Output) != want {
+		t.Fatalf("Output = %s, want %s", m.Output, want)
+	}
+
+	m.Input = m.Output
+	var out order
+	if err := h.GetInputXML(&out); err != nil {
+		t.Fatal(err)
+	}
+	in.XMLName = out.XMLName
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestSetOutputXMLWithHeader(t *testing.T) {
+	m := &testhost.MockHost{}
+	if err := m.Host().SetOutputXMLWithHeader(order{ID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(m.Output), xml.Header+"<order") {
+		t.Fatalf("Output = %s", m.Output)
+	}
+}
@@ -1,3 +1,14 @@
-module github.com/extism/extism-plugins/go-pdk
+module github.com/louloulin/Extismx/src/go-pdk
 
-go 1.19 
\ No newline at end of file
+go 1.19
+
+require (
+	github.com/fxamacker/cbor/v2 v2.7.0
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+	google.golang.org/protobuf v1.33.0
+)
+
+require (
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+)
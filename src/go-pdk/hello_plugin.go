@@ -1,14 +1,17 @@
 package main
 
 import (
-	"./extism_pdk"
+	"github.com/louloulin/Extismx/src/go-pdk/extism_pdk"
 )
 
 //export hello
 func hello() int32 {
-	// Create a host interface
-	host := extism_pdk.CreateHost()
+	return greet(extism_pdk.CreateHost())
+}
 
+// greet holds the plugin logic. It depends only on extism_pdk.HostAPI so it
+// can be unit-tested with testhost.MockHost.
+func greet(host extism_pdk.HostAPI) int32 {
 	// Get input from host
 	input := host.GetInputString()
 	if input == "" {